@@ -57,7 +57,7 @@ func errorKeyConflict(new *node, existing *node, err error) error {
 	return fmt.Errorf("key %q confilicts with %q: %w", new.pathName(), existing.pathName(), err)
 }
 
-func (c *config) set(source, key string, v string) error {
+func (c *config) set(source, key string, v string, masked bool) error {
 	trueKey, ok := c.aliases[key]
 	if ok {
 		key = trueKey
@@ -73,19 +73,39 @@ func (c *config) set(source, key string, v string) error {
 	}
 
 	n.setSource = source
-	return c.vs[key].Value.Set(v)
+
+	// references are resolved after all providers are applied
+	if _, _, isRef := reference(v); isRef && n.resolvable() {
+		n.ref = v
+		return nil
+	}
+
+	if !masked {
+		return n.Value.Set(v)
+	}
+
+	n.isResolved = true
+
+	// the error of Set may contain the value
+	if err := n.Value.Set(v); err != nil {
+		return fmt.Errorf("%w of type %q", ErrResolvedValue, n.Value.Type())
+	}
+
+	return nil
 }
 
-func (c *config) awaited() map[string]bool {
-	a := make(map[string]bool)
+func (c *config) options() map[string]Option {
+	o := make(map[string]Option, len(c.vs)+len(c.aliases))
 
-	for k := range c.vs {
-		a[k] = true
+	for k, n := range c.vs {
+		o[k] = n.option()
 	}
 
-	for k := range c.aliases {
-		a[k] = false
+	for alias, k := range c.aliases {
+		opt := c.vs[k].option()
+		opt.IsAlias = true
+		o[alias] = opt
 	}
 
-	return a
+	return o
 }
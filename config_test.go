@@ -1,11 +1,18 @@
 package zerocfg
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"os"
+	"sort"
 	"strconv"
 	"strings"
+	"sync"
 	"testing"
+	"time"
 
+	"github.com/chaindead/zerocfg/meta"
 	"github.com/stretchr/testify/require"
 )
 
@@ -294,7 +301,7 @@ func Test_ConfigError(t *testing.T) {
 				c = defaultConfig()
 				tt.setup()
 
-				return c.applyParser(mockType, tt.source)
+				return c.applyParser(mockType, tt.source, locator(newMock(nil)), masker(newMock(nil)))
 			}
 
 			if tt.isPanic {
@@ -459,3 +466,339 @@ func Test_AsYaml(t *testing.T) {
 	require.Contains(t, yamlStr, "secret:")
 	require.Contains(t, yamlStr, "key: <secret>")
 }
+
+type optionMock struct {
+	mockParser
+	options map[string]Option
+}
+
+func (m *optionMock) ProvideOptions(options map[string]Option, conv func(any) string) (f, u map[string]string, _ error) {
+	m.options = options
+
+	return m.Provide(meta.Awaited(options), conv)
+}
+
+func Test_OptionProvider(t *testing.T) {
+	c = testConfig()
+
+	Strs("hosts", nil, "hosts", Alias("h"), Required())
+	Bool("debug", false, "")
+	Str("password", "", "", Secret())
+	IP("ip", "127.0.0.1", "")
+
+	p := &optionMock{mockParser: *newMock(map[string]any{"h": []string{"a"}})}
+	err := Parse(p)
+	require.NoError(t, err)
+
+	hosts := Option{Name: "hosts", Type: "strings", Description: "hosts", IsSlice: true, IsRequired: true}
+	alias := hosts
+	alias.IsAlias = true
+
+	expected := map[string]Option{
+		"hosts":    hosts,
+		"h":        alias,
+		"debug":    {Name: "debug", Type: "bool", IsBool: true},
+		"password": {Name: "password", Type: "string", IsSecret: true},
+		"ip":       {Name: "ip", Type: "ip"},
+	}
+	require.Equal(t, expected, p.options)
+	require.Equal(t, mockType, c.vs["hosts"].setSource)
+}
+
+type ctxKey struct{}
+
+type contextMock struct {
+	mockParser
+	ctx   context.Context
+	block bool
+}
+
+func (m *contextMock) ProvideContext(ctx context.Context, options map[string]Option, conv func(any) string) (f, u map[string]string, _ error) {
+	m.ctx = ctx
+	if m.block {
+		<-ctx.Done()
+		return nil, nil, errors.New("connection failed")
+	}
+
+	return m.Provide(meta.Awaited(options), conv)
+}
+
+func Test_ParseContext(t *testing.T) {
+	c = testConfig()
+	port := Int("port", 0, "")
+
+	ctx := context.WithValue(context.Background(), ctxKey{}, "value")
+	p := &contextMock{mockParser: *newMock(map[string]any{"port": 80})}
+	err := ParseContext(ctx, p)
+	require.NoError(t, err)
+	require.Equal(t, "value", p.ctx.Value(ctxKey{}))
+	require.Equal(t, 80, *port)
+}
+
+func Test_ParseContextError(t *testing.T) {
+	c = testConfig()
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	err := ParseContext(ctx, &contextMock{mockParser: *newMock(nil), block: true})
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+	require.EqualError(t, err, `parse "mock": context deadline exceeded: connection failed`)
+
+	// providers are not called once ctx is done
+	c = testConfig()
+	ctx, cancel = context.WithCancel(context.Background())
+	cancel()
+
+	p := &optionMock{mockParser: *newMock(nil)}
+	err = ParseContext(ctx, p)
+	require.ErrorIs(t, err, context.Canceled)
+	require.Nil(t, p.options)
+}
+
+// parallelMock returns values only after all providers sharing started are called.
+type parallelMock struct {
+	mockParser
+	name    string
+	started *sync.WaitGroup
+	err     error
+}
+
+func (m parallelMock) Type() string {
+	return m.name
+}
+
+func (m parallelMock) Provide(awaited map[string]bool, conv func(any) string) (f, u map[string]string, _ error) {
+	m.started.Done()
+
+	done := make(chan struct{})
+	go func() {
+		m.started.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		return nil, nil, errors.New("providers are not called concurrently")
+	}
+
+	if m.err != nil {
+		return nil, nil, m.err
+	}
+
+	return m.mockParser.Provide(awaited, conv)
+}
+
+func Test_ParseParallel(t *testing.T) {
+	c = testConfig()
+	port := Int("port", 0, "")
+	host := Str("host", "", "")
+
+	var started sync.WaitGroup
+	started.Add(3)
+	err := ParseParallel(context.Background(),
+		parallelMock{*newMock(map[string]any{"port": 1}), "first", &started, nil},
+		parallelMock{*newMock(map[string]any{"port": 2, "host": "localhost"}), "second", &started, nil},
+		parallelMock{*newMock(map[string]any{"port": 3, "host": "remote"}), "third", &started, nil},
+	)
+	require.NoError(t, err)
+
+	// priority is the order of providers
+	require.Equal(t, 1, *port)
+	require.Equal(t, "localhost", *host)
+	require.Equal(t, "first", c.vs["port"].setSource)
+	require.Equal(t, "second", c.vs["host"].setSource)
+}
+
+func Test_ParseParallelError(t *testing.T) {
+	c = testConfig()
+	port := Int("port", 0, "")
+
+	errFirst, errThird := errors.New("first failed"), errors.New("third failed")
+
+	var started sync.WaitGroup
+	started.Add(3)
+	err := ParseParallel(context.Background(),
+		parallelMock{*newMock(nil), "first", &started, errFirst},
+		parallelMock{*newMock(map[string]any{"port": 2}), "second", &started, nil},
+		parallelMock{*newMock(nil), "third", &started, errThird},
+	)
+	require.ErrorIs(t, err, errFirst)
+	require.ErrorIs(t, err, errThird)
+	require.EqualError(t, err, "parse \"first\": first failed\nparse \"third\": third failed")
+
+	// nothing is applied if any provider fails
+	require.Equal(t, 0, *port)
+}
+
+type locatedMock struct {
+	mockParser
+}
+
+func (m locatedMock) Locate(key string) (string, bool) {
+	return "file.yaml", key != "unlocated"
+}
+
+func Test_Locator(t *testing.T) {
+	c = testConfig()
+	Int("port", 0, "")
+
+	err := Parse(locatedMock{*newMock(map[string]any{"stray": 1, "unlocated": 2})})
+	u, ok := IsUnknown(err)
+	require.True(t, ok)
+
+	// keys are bare, locations are only in the message
+	sort.Strings(u[mockType])
+	require.Equal(t, []string{"stray", "unlocated"}, u[mockType])
+	require.Contains(t, err.Error(), `"stray (file.yaml)"`)
+	require.Contains(t, err.Error(), `"unlocated"`)
+
+	c = testConfig()
+	Int("port", 0, "")
+
+	err = Parse(locatedMock{*newMock(map[string]any{"port": "abc"})})
+	require.ErrorContains(t, err, `set key="port" (file.yaml)`)
+}
+
+type sourcedMock struct {
+	mockParser
+}
+
+func (m sourcedMock) Source(key string) (string, bool) {
+	if strings.HasPrefix(key, "a") {
+		return "file_a", true
+	}
+
+	return "", false
+}
+
+func Test_Sourcer(t *testing.T) {
+	c = testConfig()
+	Int("a1", 0, "desc")
+	Int("b1", 0, "")
+
+	err := Parse(sourcedMock{*newMock(map[string]any{"a1": 1, "b1": 1, "a2": 2, "b2": 2})})
+	u, ok := IsUnknown(err)
+	require.True(t, ok)
+	require.EqualValues(t, UnknownFieldError{"file_a": {"a2"}, mockType: {"b2"}}, u)
+
+	require.Equal(t, "file_a", c.vs["a1"].setSource)
+	require.Equal(t, mockType, c.vs["b1"].setSource)
+
+	r := Show()
+	require.Contains(t, r, "desc [file_a]")
+	require.Contains(t, r, "["+mockType+"]")
+}
+
+type maskedMock struct {
+	mockParser
+}
+
+func (m maskedMock) Masked(key string) bool {
+	return key != "plain"
+}
+
+func Test_Masker(t *testing.T) {
+	c = testConfig()
+	pass := Str("db.password", "", "")
+	plain := Str("plain", "", "")
+
+	err := Parse(maskedMock{*newMock(map[string]any{"db.password": "hunter2", "plain": "visible"})})
+	require.NoError(t, err)
+
+	require.Equal(t, "hunter2", *pass)
+	require.Equal(t, "visible", *plain)
+
+	r := Show()
+	require.NotContains(t, r, "hunter2")
+	require.Contains(t, r, "visible")
+
+	c = testConfig()
+	Int("db.port", 0, "")
+
+	err = Parse(maskedMock{*newMock(map[string]any{"db.port": "hunter2"})})
+	require.EqualError(t, err, `apply "mock": set key="db.port": invalid resolved value of type "int"`)
+	require.ErrorIs(t, err, ErrResolvedValue)
+}
+
+func Test_Resolve(t *testing.T) {
+	secretFile := t.TempDir() + "/db"
+	require.NoError(t, os.WriteFile(secretFile, []byte("file-pass\n"), 0o600))
+	t.Setenv("ZFG_TEST_TOKEN", "env-token")
+
+	c = testConfig()
+	pass := Str("db.password", "", "", Secret())
+	token := Str("api.token", "", "", Resolvable())
+	plain := Str("plain", "", "")
+	url := Str("url", "", "", Secret())
+
+	err := Parse(newMock(map[string]any{
+		"db.password": "file://" + secretFile,
+		"api.token":   "env://ZFG_TEST_TOKEN",
+		"plain":       "env://ZFG_TEST_TOKEN",
+		"url":         "https://example.com",
+	}))
+	require.NoError(t, err)
+
+	require.Equal(t, "file-pass", *pass)
+	require.Equal(t, "env-token", *token)
+	require.Equal(t, "env://ZFG_TEST_TOKEN", *plain)
+	require.Equal(t, "https://example.com", *url)
+	require.Equal(t, mockType, c.vs["api.token"].setSource)
+	require.NotContains(t, Show(), "env-token")
+}
+
+func Test_ResolveError(t *testing.T) {
+	t.Setenv("ZFG_TEST_PORT", "secret-port")
+
+	tests := []struct {
+		name   string
+		value  string
+		expect string
+		is     error
+	}{
+		{
+			name:   "unset variable",
+			value:  "env://ZFG_TEST_UNSET",
+			expect: `resolve key="port" from env reference: variable "ZFG_TEST_UNSET" is not set`,
+		},
+		{
+			name:   "invalid value",
+			value:  "env://ZFG_TEST_PORT",
+			expect: `resolve key="port" from env reference: invalid resolved value of type "int"`,
+			is:     ErrResolvedValue,
+		},
+		{
+			name:   "exec not registered",
+			value:  "exec://echo secret-port",
+			expect: `resolve key="port" from exec reference: no resolver registered`,
+			is:     ErrNoResolver,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c = testConfig()
+			Int("port", 0, "", Resolvable())
+
+			err := Parse(newMock(map[string]any{"port": tt.value}))
+			require.EqualError(t, err, tt.expect)
+			require.NotContains(t, err.Error(), "secret-port")
+			if tt.is != nil {
+				require.ErrorIs(t, err, tt.is)
+			}
+		})
+	}
+}
+
+func Test_RegisterResolver(t *testing.T) {
+	RegisterResolver("exec", ExecResolver)
+	defer delete(resolvers, "exec")
+
+	c = testConfig()
+	v := Str("v", "", "", Resolvable())
+
+	err := Parse(newMock(map[string]any{"v": "exec://echo hello"}))
+	require.NoError(t, err)
+	require.Equal(t, "hello", *v)
+}
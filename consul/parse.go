@@ -0,0 +1,288 @@
+package consul
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/chaindead/zerocfg/meta"
+	"github.com/chaindead/zerocfg/util"
+)
+
+const (
+	defaultTimeout = 10 * time.Second
+	defaultWait    = 5 * time.Minute
+	retryInterval  = 5 * time.Second
+)
+
+var errStatus = errors.New("unexpected status")
+
+type Opt func(*Provider)
+
+// WithToken returns an Opt that authorizes requests with an ACL token.
+func WithToken(token string) Opt {
+	return func(p *Provider) {
+		p.token = token
+	}
+}
+
+// WithDatacenter returns an Opt that reads keys of a datacenter instead of the agent's one.
+func WithDatacenter(dc string) Opt {
+	return func(p *Provider) {
+		p.dc = dc
+	}
+}
+
+// WithTimeout returns an Opt that limits the duration of reading the prefix, 10 seconds by default.
+// Blocking queries of Watch are limited to the wait time plus timeout.
+func WithTimeout(d time.Duration) Opt {
+	return func(p *Provider) {
+		p.timeout = d
+	}
+}
+
+// WithWait returns an Opt that sets the maximum duration of a blocking query of Watch, 5 minutes by default.
+func WithWait(d time.Duration) Opt {
+	return func(p *Provider) {
+		p.wait = d
+	}
+}
+
+// WithClient returns an Opt that sets the http client of the agent API, e.g. for an agent listening on HTTPS.
+func WithClient(client *http.Client) Opt {
+	return func(p *Provider) {
+		p.client = client
+	}
+}
+
+// Provider reads keys of a Consul KV prefix via the HTTP API.
+type Provider struct {
+	addr    *string
+	prefix  *string
+	token   string
+	dc      string
+	timeout time.Duration
+	wait    time.Duration
+	client  *http.Client
+
+	mu    sync.Mutex
+	index uint64
+	last  map[string]string
+	keys  map[string]string // option key -> consul key
+}
+
+type pair struct {
+	Key   string
+	Value []byte // base64 in json
+}
+
+// New creates a Provider reading all keys under prefix from the Consul agent at addr (e.g. "http://127.0.0.1:8500").
+//
+// Key paths relative to prefix map to option names with slashes replaced by dots,
+// so "app/db/host" with prefix "app" sets option "db.host". Folder keys (ending with "/") are skipped.
+func New(addr, prefix *string, opts ...Opt) *Provider {
+	p := &Provider{
+		addr:    addr,
+		prefix:  prefix,
+		timeout: defaultTimeout,
+		wait:    defaultWait,
+		client:  http.DefaultClient,
+	}
+
+	for _, opt := range opts {
+		opt(p)
+	}
+
+	return p
+}
+
+func (p *Provider) Type() string {
+	return fmt.Sprintf("consul[%s]", p.root())
+}
+
+// Locate returns the Consul key of an option.
+func (p *Provider) Locate(key string) (string, bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	k, ok := p.keys[key]
+	return k, ok
+}
+
+func (p *Provider) Provide(keys map[string]bool, conv func(any) string) (found, unknown map[string]string, err error) {
+	return p.provide(context.Background(), keys, conv)
+}
+
+// ProvideContext reads the keys of the prefix with ctx, limited by WithTimeout too.
+func (p *Provider) ProvideContext(ctx context.Context, options map[string]meta.Option, conv func(any) string) (found, unknown map[string]string, err error) {
+	return p.provide(ctx, meta.Awaited(options), conv)
+}
+
+func (p *Provider) provide(ctx context.Context, keys map[string]bool, conv func(any) string) (found, unknown map[string]string, err error) {
+	pairs, index, err := p.fetch(ctx, 0)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	settings := p.remember(pairs, index)
+
+	found, unknown = util.Split(settings, keys, conv)
+
+	return found, unknown, nil
+}
+
+// Watch waits for changes of the prefix with blocking queries and calls onChange when any key changes,
+// until ctx is done. Failed queries are retried after a delay.
+func (p *Provider) Watch(ctx context.Context, onChange func()) error {
+	for {
+		p.mu.Lock()
+		index, last := p.index, p.last
+		p.mu.Unlock()
+
+		pairs, newIndex, err := p.fetch(ctx, index)
+		if err != nil {
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-time.After(retryInterval):
+			}
+
+			continue
+		}
+
+		// index going backwards means the raft state was reset, start over,
+		// zero index would turn blocking queries into busy polling
+		switch {
+		case newIndex < index:
+			newIndex = 0
+		case newIndex == 0:
+			newIndex = 1
+		}
+
+		settings := p.remember(pairs, newIndex)
+		if !reflect.DeepEqual(settings, last) {
+			onChange()
+		}
+	}
+}
+
+// remember maps pairs to option names and stores them with the index of the query.
+func (p *Provider) remember(pairs []pair, index uint64) map[string]string {
+	settings := make(map[string]string)
+	keys := make(map[string]string)
+	for _, kv := range pairs {
+		key, ok := p.key(kv.Key)
+		if !ok {
+			continue
+		}
+
+		settings[key] = string(kv.Value)
+		keys[key] = kv.Key
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.last = settings
+	p.keys = keys
+	p.index = index
+
+	return settings
+}
+
+// fetch reads the prefix, a non-zero index makes a blocking query returning after a change or the wait time.
+func (p *Provider) fetch(ctx context.Context, index uint64) ([]pair, uint64, error) {
+	timeout := p.timeout
+	if index > 0 {
+		timeout += p.wait
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	u, err := p.url(index)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
+	if err != nil {
+		return nil, 0, fmt.Errorf("create request: %w", err)
+	}
+	if p.token != "" {
+		req.Header.Set("X-Consul-Token", p.token)
+	}
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return nil, 0, fmt.Errorf("fetch: %w", err)
+	}
+	defer resp.Body.Close()
+
+	newIndex, _ := strconv.ParseUint(resp.Header.Get("X-Consul-Index"), 10, 64)
+
+	// missing prefix has no keys
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, newIndex, nil
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, 0, fmt.Errorf("%w: %s", errStatus, resp.Status)
+	}
+
+	var pairs []pair
+	if err := json.NewDecoder(resp.Body).Decode(&pairs); err != nil {
+		return nil, 0, fmt.Errorf("decode response: %w", err)
+	}
+
+	return pairs, newIndex, nil
+}
+
+func (p *Provider) url(index uint64) (string, error) {
+	u, err := url.Parse(*p.addr)
+	if err != nil {
+		return "", fmt.Errorf("parse address: %w", err)
+	}
+
+	u.Path = strings.TrimRight(u.Path, "/") + "/v1/kv/" + p.root()
+
+	q := url.Values{"recurse": {""}}
+	if p.dc != "" {
+		q.Set("dc", p.dc)
+	}
+	if index > 0 {
+		q.Set("index", strconv.FormatUint(index, 10))
+		q.Set("wait", fmt.Sprintf("%ds", int(p.wait/time.Second)))
+	}
+	u.RawQuery = q.Encode()
+
+	return u.String(), nil
+}
+
+// root returns the prefix as a folder path ("app/") or an empty string for the whole tree.
+func (p *Provider) root() string {
+	prefix := strings.Trim(*p.prefix, "/")
+	if prefix == "" {
+		return ""
+	}
+
+	return prefix + "/"
+}
+
+// key maps a consul key to an option name, folders and keys outside of the prefix are skipped.
+func (p *Provider) key(consulKey string) (string, bool) {
+	rest, ok := strings.CutPrefix(consulKey, p.root())
+	if !ok || rest == "" || strings.HasSuffix(rest, "/") {
+		return "", false
+	}
+
+	return strings.ReplaceAll(rest, "/", "."), true
+}
@@ -0,0 +1,247 @@
+package consul_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	zfg "github.com/chaindead/zerocfg"
+	"github.com/chaindead/zerocfg/consul"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeConsul implements the subset of the Consul KV API used by the provider.
+type fakeConsul struct {
+	mu      sync.Mutex
+	changed chan struct{}
+	index   uint64
+	kv      map[string]string
+	token   string
+}
+
+func newFake(kv map[string]string) *fakeConsul {
+	return &fakeConsul{kv: kv, index: 1, changed: make(chan struct{})}
+}
+
+func (f *fakeConsul) put(key, value string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.kv[key] = value
+	f.index++
+	close(f.changed)
+	f.changed = make(chan struct{})
+}
+
+func (f *fakeConsul) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if f.token != "" && r.Header.Get("X-Consul-Token") != f.token {
+		w.WriteHeader(http.StatusForbidden)
+		return
+	}
+
+	prefix := strings.TrimPrefix(r.URL.Path, "/v1/kv/")
+
+	f.mu.Lock()
+	index, changed := f.index, f.changed
+	f.mu.Unlock()
+
+	if wait, _ := strconv.ParseUint(r.URL.Query().Get("index"), 10, 64); wait >= index {
+		select {
+		case <-changed:
+		case <-time.After(time.Second):
+		case <-r.Context().Done():
+			return
+		}
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	type pair struct {
+		Key   string
+		Value []byte
+	}
+
+	var pairs []pair
+	for k, v := range f.kv {
+		if strings.HasPrefix(k, prefix) {
+			pairs = append(pairs, pair{Key: k, Value: []byte(v)})
+		}
+	}
+
+	w.Header().Set("X-Consul-Index", strconv.FormatUint(f.index, 10))
+	if len(pairs) == 0 {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+
+	_ = json.NewEncoder(w).Encode(pairs)
+}
+
+func TestProvide(t *testing.T) {
+	tests := []struct {
+		name    string
+		prefix  string
+		kv      map[string]string
+		awaited map[string]bool
+		found   map[string]string
+		unknown map[string]string
+	}{
+		{
+			name:   "nested keys",
+			prefix: "app",
+			kv: map[string]string{
+				"app/":            "",
+				"app/db/":         "",
+				"app/db/host":     "localhost",
+				"app/db/port":     "5432",
+				"application/key": "other",
+			},
+			awaited: map[string]bool{"db.host": true},
+			found:   map[string]string{"db.host": "localhost"},
+			unknown: map[string]string{"db.port": "5432"},
+		},
+		{
+			name:    "prefix with slashes",
+			prefix:  "/app/config/",
+			kv:      map[string]string{"app/config/name": "svc"},
+			awaited: map[string]bool{"name": true},
+			found:   map[string]string{"name": "svc"},
+		},
+		{
+			name:    "whole tree",
+			kv:      map[string]string{"db/host": "localhost"},
+			awaited: map[string]bool{"db.host": true},
+			found:   map[string]string{"db.host": "localhost"},
+		},
+		{
+			name:    "json value",
+			prefix:  "app",
+			kv:      map[string]string{"app/hosts": `["a","b"]`},
+			awaited: map[string]bool{"hosts": true},
+			found:   map[string]string{"hosts": `["a","b"]`},
+		},
+		{
+			name:   "missing prefix",
+			prefix: "missing",
+			kv:     map[string]string{"app/db/host": "localhost"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.found == nil {
+				tt.found = map[string]string{}
+			}
+			if tt.unknown == nil {
+				tt.unknown = map[string]string{}
+			}
+
+			srv := httptest.NewServer(newFake(tt.kv))
+			defer srv.Close()
+
+			p := consul.New(&srv.URL, &tt.prefix)
+
+			found, unknown, err := p.Provide(tt.awaited, zfg.ToString)
+			require.NoError(t, err)
+			assert.Equal(t, tt.found, found)
+			assert.Equal(t, tt.unknown, unknown)
+		})
+	}
+}
+
+func TestToken(t *testing.T) {
+	fake := newFake(map[string]string{"app/db/host": "localhost"})
+	fake.token = "secret"
+
+	srv := httptest.NewServer(fake)
+	defer srv.Close()
+
+	prefix := "app"
+
+	_, _, err := consul.New(&srv.URL, &prefix).Provide(map[string]bool{}, zfg.ToString)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "unexpected status: 403 Forbidden")
+
+	found, _, err := consul.New(&srv.URL, &prefix, consul.WithToken("secret")).
+		Provide(map[string]bool{"db.host": true}, zfg.ToString)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"db.host": "localhost"}, found)
+}
+
+func TestLocate(t *testing.T) {
+	srv := httptest.NewServer(newFake(map[string]string{"app/db/host": "localhost"}))
+	defer srv.Close()
+
+	prefix := "app"
+	p := consul.New(&srv.URL, &prefix)
+
+	_, _, err := p.Provide(map[string]bool{}, zfg.ToString)
+	require.NoError(t, err)
+
+	loc, ok := p.Locate("db.host")
+	require.True(t, ok)
+	assert.Equal(t, "app/db/host", loc)
+	assert.Equal(t, "consul[app/]", p.Type())
+}
+
+func TestProvideContext(t *testing.T) {
+	srv := httptest.NewServer(newFake(map[string]string{"app/db/host": "localhost"}))
+	defer srv.Close()
+
+	prefix := "app"
+	options := map[string]zfg.Option{"db.host": {Name: "db.host", Type: "string"}}
+
+	found, _, err := consul.New(&srv.URL, &prefix).ProvideContext(context.Background(), options, zfg.ToString)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"db.host": "localhost"}, found)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, _, err = consul.New(&srv.URL, &prefix).ProvideContext(ctx, options, zfg.ToString)
+	require.ErrorIs(t, err, context.Canceled)
+}
+
+func TestWatch(t *testing.T) {
+	fake := newFake(map[string]string{"app/db/host": "localhost"})
+	srv := httptest.NewServer(fake)
+	defer srv.Close()
+
+	prefix := "app"
+	p := consul.New(&srv.URL, &prefix, consul.WithWait(time.Second))
+
+	_, _, err := p.Provide(map[string]bool{}, zfg.ToString)
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	changed := make(chan struct{}, 1)
+	go func() {
+		_ = p.Watch(ctx, func() { changed <- struct{}{} })
+	}()
+
+	// unrelated key does not change the prefix
+	fake.put("other/key", "value")
+
+	select {
+	case <-changed:
+		t.Fatal("unexpected change")
+	case <-time.After(100 * time.Millisecond):
+	}
+
+	fake.put("app/db/host", "db.local")
+
+	select {
+	case <-changed:
+	case <-ctx.Done():
+		t.Fatal("change not detected")
+	}
+}
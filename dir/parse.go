@@ -0,0 +1,110 @@
+package dir
+
+import (
+	"fmt"
+	"io/fs"
+	"strings"
+
+	"github.com/chaindead/zerocfg/util"
+)
+
+// k8sPrefix marks entries of Kubernetes atomic writer (e.g. "..data", "..2024_01_01_00_00_00.000").
+const k8sPrefix = ".."
+
+type Opt func(*Provider)
+
+// WithFS returns an Opt that reads the directory from fsys instead of the operating system.
+// The path is slash-separated and relative to the root of fsys.
+func WithFS(fsys fs.FS) Opt {
+	return func(p *Provider) {
+		p.fsys = fsys
+	}
+}
+
+type Provider struct {
+	path *string
+	fsys fs.FS
+
+	files map[string]string
+}
+
+// New creates a Provider reading a directory with a file per option,
+// as Kubernetes mounts ConfigMaps and Secrets.
+//
+// File names map to option keys, nested paths are joined with dots, so both
+// "db.host" and "db/host" set option "db.host". Trailing newlines of values are trimmed
+// and "..data" entries of Kubernetes mounts are ignored.
+func New(path *string, opts ...Opt) *Provider {
+	p := &Provider{path: path}
+	for _, opt := range opts {
+		opt(p)
+	}
+
+	return p
+}
+
+func (p *Provider) Type() string {
+	return fmt.Sprintf("dir[%s]", util.ShortenPath(*p.path))
+}
+
+// Locate returns the file of a key.
+func (p *Provider) Locate(key string) (string, bool) {
+	f, ok := p.files[key]
+	return f, ok
+}
+
+func (p *Provider) Provide(keys map[string]bool, conv func(any) string) (found, unknown map[string]string, err error) {
+	p.files = make(map[string]string)
+
+	settings := make(map[string]string)
+	if err = p.read(*p.path, "", settings); err != nil {
+		return nil, nil, err
+	}
+
+	found, unknown = util.Split(settings, keys, conv)
+
+	return found, unknown, nil
+}
+
+func (p *Provider) read(dir, prefix string, settings map[string]string) error {
+	entries, err := util.ReadDir(p.fsys, dir)
+	if err != nil {
+		return fmt.Errorf("read dir: %w", err)
+	}
+
+	for _, e := range entries {
+		if strings.HasPrefix(e.Name(), k8sPrefix) {
+			continue
+		}
+
+		path := util.Join(p.fsys, dir, e.Name())
+		key := e.Name()
+		if prefix != "" {
+			key = prefix + "." + key
+		}
+
+		// follow symlinks, mounted files are links into "..data"
+		info, err := util.Stat(p.fsys, path)
+		if err != nil {
+			return fmt.Errorf("read dir: %w", err)
+		}
+
+		if info.IsDir() {
+			if err := p.read(path, key, settings); err != nil {
+				return err
+			}
+
+			continue
+		}
+
+		data, err := util.ReadFile(p.fsys, path)
+		if err != nil {
+			return fmt.Errorf("read dir: %w", err)
+		}
+
+		settings[key] = strings.TrimRight(string(data), "\r\n")
+		p.files[key] = path
+	}
+
+	return nil
+}
@@ -0,0 +1,79 @@
+package dir_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"testing/fstest"
+
+	zfg "github.com/chaindead/zerocfg"
+	"github.com/chaindead/zerocfg/dir"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParse(t *testing.T) {
+	root := t.TempDir()
+	writeFile(t, root, "db.host", "localhost\n")
+	writeFile(t, root, "db/port", "5432\r\n")
+	writeFile(t, root, "multi", "a\nb\n\n")
+	writeFile(t, root, "stray", "x")
+
+	awaited := map[string]bool{"db.host": true, "db.port": true, "multi": true}
+	p := dir.New(&root)
+
+	found, unknown, err := p.Provide(awaited, zfg.ToString)
+	require.NoError(t, err)
+
+	assert.Equal(t, map[string]string{"db.host": "localhost", "db.port": "5432", "multi": "a\nb"}, found)
+	assert.Equal(t, map[string]string{"stray": "x"}, unknown)
+
+	loc, ok := p.Locate("db.port")
+	assert.True(t, ok)
+	assert.Equal(t, filepath.Join(root, "db", "port"), loc)
+}
+
+func TestParse_Kubernetes(t *testing.T) {
+	root := t.TempDir()
+
+	// layout of a ConfigMap volume written by the kubelet
+	writeFile(t, root, "..2024_01_01_00_00_00.000/db.host", "localhost\n")
+	writeFile(t, root, "..2024_01_01_00_00_00.000/db.password", "secret")
+	require.NoError(t, os.Symlink("..2024_01_01_00_00_00.000", filepath.Join(root, "..data")))
+	require.NoError(t, os.Symlink(filepath.Join("..data", "db.host"), filepath.Join(root, "db.host")))
+	require.NoError(t, os.Symlink(filepath.Join("..data", "db.password"), filepath.Join(root, "db.password")))
+
+	found, unknown, err := dir.New(&root).Provide(map[string]bool{"db.host": true}, zfg.ToString)
+	require.NoError(t, err)
+
+	assert.Equal(t, map[string]string{"db.host": "localhost"}, found)
+	assert.Equal(t, map[string]string{"db.password": "secret"}, unknown)
+}
+
+func TestParse_Error(t *testing.T) {
+	missing := filepath.Join(t.TempDir(), "missing")
+
+	_, _, err := dir.New(&missing).Provide(map[string]bool{}, zfg.ToString)
+	assert.Error(t, err)
+}
+
+func TestFS(t *testing.T) {
+	fsys := fstest.MapFS{
+		"config/db.host":   {Data: []byte("localhost\n")},
+		"config/db/port":   {Data: []byte("5432")},
+		"config/..data/x":  {Data: []byte("ignored")},
+		"other/not.loaded": {Data: []byte("x")},
+	}
+
+	root := "config"
+	found, unknown, err := dir.New(&root, dir.WithFS(fsys)).Provide(map[string]bool{"db.host": true, "db.port": true}, zfg.ToString)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"db.host": "localhost", "db.port": "5432"}, found)
+	assert.Empty(t, unknown)
+}
+
+func writeFile(t *testing.T, root, name, data string) {
+	path := filepath.Join(root, name)
+	require.NoError(t, os.MkdirAll(filepath.Dir(path), 0o755))
+	require.NoError(t, os.WriteFile(path, []byte(data), 0o644))
+}
@@ -0,0 +1,121 @@
+package docker
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"strings"
+
+	"github.com/chaindead/zerocfg/meta"
+	"github.com/chaindead/zerocfg/util"
+)
+
+const defaultDir = "/run/secrets"
+
+type Opt func(*Provider)
+
+// WithDir returns an Opt that sets the directory of secret files, "/run/secrets" by default.
+func WithDir(dir string) Opt {
+	return func(p *Provider) {
+		p.dir = dir
+	}
+}
+
+// WithNameMapping returns an Opt that sets how option names map to secret names,
+// dots are replaced with underscores by default ("db.password" -> "db_password").
+func WithNameMapping(mapping func(key string) string) Opt {
+	return func(p *Provider) {
+		p.mapping = mapping
+	}
+}
+
+// WithFS returns an Opt that reads secrets from fsys instead of the operating system.
+// The directory is slash-separated and relative to the root of fsys.
+func WithFS(fsys fs.FS) Opt {
+	return func(p *Provider) {
+		p.fsys = fsys
+	}
+}
+
+// Provider reads Docker secrets of options marked with Secret.
+type Provider struct {
+	dir     string
+	mapping func(string) string
+	fsys    fs.FS
+
+	files map[string]string
+}
+
+// New creates a Provider reading Docker (Swarm) secrets.
+//
+// For every option marked with zfg.Secret, the file named by the name mapping is looked up
+// in the secrets directory, missing files are skipped. Trailing newlines of values are trimmed.
+// Other options are never read from files, so no values are unknown.
+func New(opts ...Opt) *Provider {
+	p := &Provider{
+		dir:     defaultDir,
+		mapping: defaultMapping,
+	}
+
+	for _, opt := range opts {
+		opt(p)
+	}
+
+	return p
+}
+
+func defaultMapping(key string) string {
+	return strings.ReplaceAll(key, ".", "_")
+}
+
+func (p *Provider) Type() string {
+	return fmt.Sprintf("docker[%s]", util.ShortenPath(p.dir))
+}
+
+// Locate returns the file of a key.
+func (p *Provider) Locate(key string) (string, bool) {
+	f, ok := p.files[key]
+	return f, ok
+}
+
+// Provide reads no secrets, secret options are only known to ProvideOptions.
+func (p *Provider) Provide(keys map[string]bool, conv func(any) string) (found, unknown map[string]string, err error) {
+	return p.ProvideOptions(meta.FromAwaited(keys), conv)
+}
+
+// ProvideOptions reads secrets of options marked with Secret.
+func (p *Provider) ProvideOptions(options map[string]meta.Option, conv func(any) string) (found, unknown map[string]string, err error) {
+	secrets := make(map[string]bool, len(options))
+	for k, o := range options {
+		secrets[k] = o.IsSecret
+	}
+
+	return p.provide(meta.Awaited(options), secrets, conv)
+}
+
+func (p *Provider) provide(keys, secrets map[string]bool, conv func(any) string) (found, unknown map[string]string, err error) {
+	p.files = make(map[string]string)
+
+	found, unknown = make(map[string]string), make(map[string]string)
+	for k, isOption := range keys {
+		// aliases are skipped, they refer to the same secret
+		if !isOption || !secrets[k] {
+			continue
+		}
+
+		path := util.Join(p.fsys, p.dir, p.mapping(k))
+
+		data, err := util.ReadFile(p.fsys, path)
+		if errors.Is(err, fs.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return nil, nil, fmt.Errorf("read secret: %w", err)
+		}
+
+		found[k] = conv(strings.TrimRight(string(data), "\r\n"))
+		p.files[k] = path
+	}
+
+	return found, unknown, nil
+}
@@ -0,0 +1,107 @@
+package docker_test
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	zfg "github.com/chaindead/zerocfg"
+	"github.com/chaindead/zerocfg/docker"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParse(t *testing.T) {
+	root := t.TempDir()
+	writeFile(t, root, "db_password", "pa$$\n")
+	writeFile(t, root, "db_user", "admin")
+	writeFile(t, root, "api_key", "key")
+
+	options := map[string]zfg.Option{
+		"db.password": {Name: "db.password", IsSecret: true},
+		"db.user":     {Name: "db.user"},
+		"api.token":   {Name: "api.token", IsSecret: true},
+		"p":           {Name: "db.password", IsSecret: true, IsAlias: true},
+	}
+
+	p := docker.New(docker.WithDir(root))
+
+	found, unknown, err := p.ProvideOptions(options, zfg.ToString)
+	require.NoError(t, err)
+
+	// db.user is not secret, api.token has no file, aliases are skipped
+	assert.Equal(t, map[string]string{"db.password": "pa$$"}, found)
+	assert.Empty(t, unknown)
+
+	loc, ok := p.Locate("db.password")
+	assert.True(t, ok)
+	assert.Equal(t, filepath.Join(root, "db_password"), loc)
+}
+
+func TestNameMapping(t *testing.T) {
+	root := t.TempDir()
+	writeFile(t, root, "DB-PASSWORD", "pa$$")
+
+	p := docker.New(docker.WithDir(root), docker.WithNameMapping(func(key string) string {
+		return strings.ToUpper(strings.ReplaceAll(key, ".", "-"))
+	}))
+
+	found, _, err := p.ProvideOptions(map[string]zfg.Option{"db.password": {Name: "db.password", IsSecret: true}}, zfg.ToString)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"db.password": "pa$$"}, found)
+}
+
+func TestFS(t *testing.T) {
+	fsys := fstest.MapFS{"secrets/db_password": {Data: []byte("pa$$\n")}}
+
+	p := docker.New(docker.WithFS(fsys), docker.WithDir("secrets"))
+
+	found, _, err := p.ProvideOptions(map[string]zfg.Option{"db.password": {Name: "db.password", IsSecret: true}}, zfg.ToString)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"db.password": "pa$$"}, found)
+}
+
+func TestParse_Error(t *testing.T) {
+	root := t.TempDir()
+	require.NoError(t, os.Mkdir(filepath.Join(root, "db_password"), 0o755))
+
+	p := docker.New(docker.WithDir(root))
+
+	_, _, err := p.ProvideOptions(map[string]zfg.Option{"db.password": {Name: "db.password", IsSecret: true}}, zfg.ToString)
+	assert.ErrorContains(t, err, "read secret")
+}
+
+func TestProvide(t *testing.T) {
+	root := t.TempDir()
+	writeFile(t, root, "db_password", "pa$$")
+
+	// secret options are unknown to Provide
+	found, _, err := docker.New(docker.WithDir(root)).Provide(map[string]bool{"db.password": true}, zfg.ToString)
+	require.NoError(t, err)
+	assert.Empty(t, found)
+}
+
+func TestProvideOptions(t *testing.T) {
+	root := t.TempDir()
+	writeFile(t, root, "db_password", "pa$$")
+	writeFile(t, root, "db_user", "admin")
+
+	options := map[string]zfg.Option{
+		"db.password": {Name: "db.password", IsSecret: true},
+		"p":           {Name: "db.password", IsSecret: true, IsAlias: true},
+		"db.user":     {Name: "db.user"},
+	}
+
+	found, unknown, err := docker.New(docker.WithDir(root)).ProvideOptions(options, zfg.ToString)
+	require.NoError(t, err)
+	assert.Empty(t, unknown)
+	assert.Equal(t, map[string]string{"db.password": "pa$$"}, found)
+}
+
+func writeFile(t *testing.T, root, name, content string) {
+	t.Helper()
+
+	require.NoError(t, os.WriteFile(filepath.Join(root, name), []byte(content), 0o600))
+}
@@ -0,0 +1,152 @@
+package enc
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+const (
+	envelopePrefix = "ENC["
+	envelopeSuffix = "]"
+	algorithm      = "AES256_GCM"
+
+	// KeySize is the size of keys in bytes.
+	KeySize = 32
+
+	nonceSize = 12
+)
+
+var (
+	// ErrEnvelope is returned for malformed ENC[...] values.
+	ErrEnvelope = errors.New("malformed envelope")
+
+	// ErrDecrypt is returned when a value can not be decrypted (e.g. wrong key or corrupted value).
+	ErrDecrypt = errors.New("decryption failed")
+
+	// ErrKey is returned for keys of invalid size or encoding.
+	ErrKey = errors.New("invalid key")
+)
+
+// IsEncrypted reports whether v is an ENC[...] envelope.
+func IsEncrypted(v string) bool {
+	return strings.HasPrefix(v, envelopePrefix) && strings.HasSuffix(v, envelopeSuffix)
+}
+
+// Encrypt encrypts plaintext with a 32 byte key using AES-256-GCM and returns an envelope:
+//
+//	ENC[AES256_GCM,data:<base64>,iv:<base64>,tag:<base64>]
+func Encrypt(key []byte, plaintext string) (string, error) {
+	gcm, err := newGCM(key, nonceSize)
+	if err != nil {
+		return "", err
+	}
+
+	iv := make([]byte, nonceSize)
+	if _, err := rand.Read(iv); err != nil {
+		return "", fmt.Errorf("generate iv: %w", err)
+	}
+
+	sealed := gcm.Seal(nil, iv, []byte(plaintext), nil)
+	data, tag := sealed[:len(sealed)-gcm.Overhead()], sealed[len(sealed)-gcm.Overhead():]
+
+	return fmt.Sprintf("%s%s,data:%s,iv:%s,tag:%s%s", envelopePrefix, algorithm,
+		encode(data), encode(iv), encode(tag), envelopeSuffix), nil
+}
+
+// Decrypt decrypts an envelope produced by Encrypt.
+// Errors never contain the plaintext.
+func Decrypt(key []byte, envelope string) (string, error) {
+	if !IsEncrypted(envelope) {
+		return "", ErrEnvelope
+	}
+
+	fields := strings.Split(envelope[len(envelopePrefix):len(envelope)-len(envelopeSuffix)], ",")
+	if fields[0] != algorithm {
+		return "", fmt.Errorf("%w: unsupported algorithm %q", ErrEnvelope, fields[0])
+	}
+
+	parts := make(map[string][]byte)
+	for _, f := range fields[1:] {
+		name, value, ok := strings.Cut(f, ":")
+		if !ok {
+			return "", fmt.Errorf("%w: field %q", ErrEnvelope, f)
+		}
+
+		b, err := base64.StdEncoding.DecodeString(value)
+		if err != nil {
+			return "", fmt.Errorf("%w: field %q: %w", ErrEnvelope, name, err)
+		}
+		parts[name] = b
+	}
+
+	for _, name := range []string{"data", "iv", "tag"} {
+		if _, ok := parts[name]; !ok {
+			return "", fmt.Errorf("%w: missing %q", ErrEnvelope, name)
+		}
+	}
+
+	gcm, err := newGCM(key, len(parts["iv"]))
+	if err != nil {
+		return "", err
+	}
+	if len(parts["tag"]) != gcm.Overhead() {
+		return "", fmt.Errorf("%w: tag size %d", ErrEnvelope, len(parts["tag"]))
+	}
+
+	plaintext, err := gcm.Open(nil, parts["iv"], append(parts["data"], parts["tag"]...), nil)
+	if err != nil {
+		return "", ErrDecrypt
+	}
+
+	return string(plaintext), nil
+}
+
+// ParseKey decodes a base64 encoded key, surrounding whitespace is ignored.
+func ParseKey(s string) ([]byte, error) {
+	key, err := base64.StdEncoding.DecodeString(strings.TrimSpace(s))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrKey, err)
+	}
+
+	if len(key) != KeySize {
+		return nil, fmt.Errorf("%w: size %d, expected %d", ErrKey, len(key), KeySize)
+	}
+
+	return key, nil
+}
+
+// GenerateKey returns a random base64 encoded key.
+func GenerateKey() (string, error) {
+	key := make([]byte, KeySize)
+	if _, err := rand.Read(key); err != nil {
+		return "", fmt.Errorf("generate key: %w", err)
+	}
+
+	return encode(key), nil
+}
+
+func newGCM(key []byte, nonceSize int) (cipher.AEAD, error) {
+	if len(key) != KeySize {
+		return nil, fmt.Errorf("%w: size %d, expected %d", ErrKey, len(key), KeySize)
+	}
+
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrKey, err)
+	}
+
+	if nonceSize == 0 {
+		return nil, fmt.Errorf("%w: empty iv", ErrEnvelope)
+	}
+
+	return cipher.NewGCMWithNonceSize(block, nonceSize)
+}
+
+func encode(b []byte) string {
+	return base64.StdEncoding.EncodeToString(b)
+}
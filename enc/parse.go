@@ -0,0 +1,174 @@
+package enc
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	zfg "github.com/chaindead/zerocfg"
+	"github.com/chaindead/zerocfg/meta"
+)
+
+type Opt func(*Provider)
+
+// WithKeyFile returns an Opt that reads the base64 encoded key from a file.
+func WithKeyFile(path string) Opt {
+	return func(p *Provider) {
+		p.key = func() ([]byte, error) {
+			data, err := os.ReadFile(path)
+			if err != nil {
+				return nil, fmt.Errorf("read key file: %w", err)
+			}
+
+			return ParseKey(string(data))
+		}
+	}
+}
+
+// WithKeyEnv returns an Opt that reads the base64 encoded key from an environment variable.
+func WithKeyEnv(name string) Opt {
+	return func(p *Provider) {
+		p.key = func() ([]byte, error) {
+			v, ok := os.LookupEnv(name)
+			if !ok {
+				return nil, fmt.Errorf("%w: variable %q is not set", ErrKey, name)
+			}
+
+			return ParseKey(v)
+		}
+	}
+}
+
+// WithKey returns an Opt that sets the key.
+func WithKey(key []byte) Opt {
+	return func(p *Provider) {
+		p.key = func() ([]byte, error) {
+			return key, nil
+		}
+	}
+}
+
+// Provider decrypts ENC[...] values of another provider, other values are passed as is.
+type Provider struct {
+	p   zfg.Provider
+	key func() ([]byte, error)
+
+	decrypted map[string]bool
+}
+
+// New wraps a provider to decrypt values encrypted with Encrypt:
+//
+//	zfg.Parse(
+//		enc.New(yaml.New(path), enc.WithKeyEnv("CONFIG_KEY")),
+//	)
+//
+// The key is read once per Provide, only if the provider returns encrypted values.
+// Decrypted values are masked (see zfg.Masker): Show hides them and set errors do not include them.
+func New(p zfg.Provider, opts ...Opt) *Provider {
+	e := &Provider{
+		p: p,
+		key: func() ([]byte, error) {
+			return nil, fmt.Errorf("%w: no key configured", ErrKey)
+		},
+	}
+
+	for _, opt := range opts {
+		opt(e)
+	}
+
+	return e
+}
+
+func (e *Provider) Type() string {
+	return e.p.Type()
+}
+
+func (e *Provider) Provide(awaited map[string]bool, conv func(any) string) (found, unknown map[string]string, err error) {
+	found, unknown, err = e.p.Provide(awaited, conv)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return e.decrypt(found, unknown)
+}
+
+// ProvideOptions passes options to the wrapped provider if it implements zfg.OptionProvider.
+func (e *Provider) ProvideOptions(options map[string]zfg.Option, conv func(any) string) (found, unknown map[string]string, err error) {
+	op, ok := e.p.(zfg.OptionProvider)
+	if !ok {
+		return e.Provide(meta.Awaited(options), conv)
+	}
+
+	found, unknown, err = op.ProvideOptions(options, conv)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return e.decrypt(found, unknown)
+}
+
+// ProvideContext passes ctx to the wrapped provider if it implements zfg.ContextProvider.
+func (e *Provider) ProvideContext(ctx context.Context, options map[string]zfg.Option, conv func(any) string) (found, unknown map[string]string, err error) {
+	cp, ok := e.p.(zfg.ContextProvider)
+	if !ok {
+		return e.ProvideOptions(options, conv)
+	}
+
+	found, unknown, err = cp.ProvideContext(ctx, options, conv)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return e.decrypt(found, unknown)
+}
+
+func (e *Provider) decrypt(found, unknown map[string]string) (map[string]string, map[string]string, error) {
+	var key []byte
+	var err error
+	decrypted := make(map[string]bool)
+	for k, v := range found {
+		if !IsEncrypted(v) {
+			continue
+		}
+
+		if key == nil {
+			if key, err = e.key(); err != nil {
+				return nil, nil, err
+			}
+		}
+
+		plaintext, err := Decrypt(key, v)
+		if err != nil {
+			return nil, nil, fmt.Errorf("decrypt key=%q: %w", k, err)
+		}
+
+		found[k] = plaintext
+		decrypted[k] = true
+	}
+	e.decrypted = decrypted
+
+	return found, unknown, nil
+}
+
+// Masked reports whether the value of a key was decrypted by the last Provide.
+func (e *Provider) Masked(key string) bool {
+	return e.decrypted[key]
+}
+
+// Locate returns the location of a key if the wrapped provider implements zfg.Locator.
+func (e *Provider) Locate(key string) (string, bool) {
+	if l, ok := e.p.(zfg.Locator); ok {
+		return l.Locate(key)
+	}
+
+	return "", false
+}
+
+// Source returns the source of a key if the wrapped provider implements zfg.Sourcer.
+func (e *Provider) Source(key string) (string, bool) {
+	if s, ok := e.p.(zfg.Sourcer); ok {
+		return s.Source(key)
+	}
+
+	return "", false
+}
@@ -0,0 +1,220 @@
+package enc_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	zfg "github.com/chaindead/zerocfg"
+	"github.com/chaindead/zerocfg/enc"
+	"github.com/chaindead/zerocfg/meta"
+	"github.com/chaindead/zerocfg/static"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testKey(t *testing.T) (string, []byte) {
+	t.Helper()
+
+	encoded, err := enc.GenerateKey()
+	require.NoError(t, err)
+
+	key, err := enc.ParseKey(encoded)
+	require.NoError(t, err)
+
+	return encoded, key
+}
+
+func TestEncrypt(t *testing.T) {
+	_, key := testKey(t)
+
+	for _, plaintext := range []string{"", "secret", "пароль,with:commas]"} {
+		v, err := enc.Encrypt(key, plaintext)
+		require.NoError(t, err)
+		assert.True(t, enc.IsEncrypted(v))
+		assert.True(t, strings.HasPrefix(v, "ENC[AES256_GCM,data:"))
+
+		got, err := enc.Decrypt(key, v)
+		require.NoError(t, err)
+		assert.Equal(t, plaintext, got)
+	}
+}
+
+func TestDecrypt_Errors(t *testing.T) {
+	_, key := testKey(t)
+	_, other := testKey(t)
+
+	v, err := enc.Encrypt(key, "secret")
+	require.NoError(t, err)
+
+	tests := []struct {
+		name     string
+		key      []byte
+		envelope string
+		err      error
+	}{
+		{name: "wrong key", key: other, envelope: v, err: enc.ErrDecrypt},
+		{name: "short key", key: key[:16], envelope: v, err: enc.ErrKey},
+		{name: "not envelope", key: key, envelope: "secret", err: enc.ErrEnvelope},
+		{name: "algorithm", key: key, envelope: strings.Replace(v, "AES256_GCM", "PGP", 1), err: enc.ErrEnvelope},
+		{name: "missing tag", key: key, envelope: v[:strings.Index(v, ",tag:")] + "]", err: enc.ErrEnvelope},
+		{name: "bad base64", key: key, envelope: "ENC[AES256_GCM,data:!,iv:AAAA,tag:AAAA]", err: enc.ErrEnvelope},
+		{name: "tampered", key: key, envelope: strings.Replace(v, "data:", "data:AAAA", 1), err: enc.ErrDecrypt},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := enc.Decrypt(tt.key, tt.envelope)
+			require.ErrorIs(t, err, tt.err)
+			assert.NotContains(t, err.Error(), "secret")
+		})
+	}
+}
+
+func TestParseKey(t *testing.T) {
+	encoded, key := testKey(t)
+
+	got, err := enc.ParseKey(" " + encoded + "\n")
+	require.NoError(t, err)
+	assert.Equal(t, key, got)
+
+	_, err = enc.ParseKey("c2hvcnQ=")
+	require.ErrorIs(t, err, enc.ErrKey)
+
+	_, err = enc.ParseKey("not base64")
+	require.ErrorIs(t, err, enc.ErrKey)
+}
+
+func TestProvide(t *testing.T) {
+	encoded, key := testKey(t)
+
+	password, err := enc.Encrypt(key, "pa$$")
+	require.NoError(t, err)
+
+	keyFile := filepath.Join(t.TempDir(), "key")
+	require.NoError(t, os.WriteFile(keyFile, []byte(encoded+"\n"), 0o600))
+	t.Setenv("ZFG_TEST_KEY", encoded)
+
+	values := map[string]any{
+		"db.password": password,
+		"db.user":     "admin",
+		"stray":       password,
+	}
+	awaited := map[string]bool{"db.password": true, "db.user": true}
+
+	for name, opt := range map[string]enc.Opt{
+		"key":      enc.WithKey(key),
+		"key file": enc.WithKeyFile(keyFile),
+		"key env":  enc.WithKeyEnv("ZFG_TEST_KEY"),
+	} {
+		t.Run(name, func(t *testing.T) {
+			p := enc.New(static.New(values), opt)
+
+			found, unknown, err := p.Provide(awaited, zfg.ToString)
+			require.NoError(t, err)
+			assert.Equal(t, map[string]string{"db.password": "pa$$", "db.user": "admin"}, found)
+			assert.Equal(t, map[string]string{"stray": password}, unknown)
+			assert.Equal(t, "static", p.Type())
+
+			// decrypted values are masked in Show and set errors
+			assert.True(t, p.Masked("db.password"))
+			assert.False(t, p.Masked("db.user"))
+		})
+	}
+}
+
+func TestProvide_Errors(t *testing.T) {
+	_, key := testKey(t)
+	_, other := testKey(t)
+
+	password, err := enc.Encrypt(key, "pa$$")
+	require.NoError(t, err)
+
+	awaited := map[string]bool{"db.password": true}
+
+	// key is not needed without encrypted values
+	_, _, err = enc.New(static.New(map[string]any{"db.password": "plain"})).Provide(awaited, zfg.ToString)
+	require.NoError(t, err)
+
+	_, _, err = enc.New(static.New(map[string]any{"db.password": password})).Provide(awaited, zfg.ToString)
+	require.ErrorIs(t, err, enc.ErrKey)
+
+	_, _, err = enc.New(static.New(map[string]any{"db.password": password}), enc.WithKeyEnv("ZFG_TEST_UNSET")).
+		Provide(awaited, zfg.ToString)
+	require.ErrorContains(t, err, `variable "ZFG_TEST_UNSET" is not set`)
+
+	_, _, err = enc.New(static.New(map[string]any{"db.password": password}), enc.WithKey(other)).
+		Provide(awaited, zfg.ToString)
+	require.EqualError(t, err, `decrypt key="db.password": decryption failed`)
+}
+
+// recorder is a zfg.OptionProvider recording options it receives.
+type recorder struct {
+	*static.Provider
+	options map[string]zfg.Option
+}
+
+func (r *recorder) ProvideOptions(options map[string]zfg.Option, conv func(any) string) (found, unknown map[string]string, err error) {
+	r.options = options
+
+	return r.Provide(meta.Awaited(options), conv)
+}
+
+func TestProvideOptions(t *testing.T) {
+	_, key := testKey(t)
+
+	password, err := enc.Encrypt(key, "pa$$")
+	require.NoError(t, err)
+
+	options := map[string]zfg.Option{"db.password": {Name: "db.password", Type: "string", IsSecret: true}}
+
+	// options reach the wrapped provider
+	r := &recorder{Provider: static.New(map[string]any{"db.password": password})}
+	found, _, err := enc.New(r, enc.WithKey(key)).ProvideOptions(options, zfg.ToString)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"db.password": "pa$$"}, found)
+	assert.Equal(t, options, r.options)
+
+	found, _, err = enc.New(static.New(map[string]any{"db.password": password}), enc.WithKey(key)).
+		ProvideOptions(options, zfg.ToString)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"db.password": "pa$$"}, found)
+}
+
+// ctxRecorder is a zfg.ContextProvider recording the context it receives.
+type ctxRecorder struct {
+	*static.Provider
+	ctx context.Context
+}
+
+func (r *ctxRecorder) ProvideContext(ctx context.Context, options map[string]zfg.Option, conv func(any) string) (found, unknown map[string]string, err error) {
+	r.ctx = ctx
+
+	return r.Provide(meta.Awaited(options), conv)
+}
+
+func TestProvideContext(t *testing.T) {
+	_, key := testKey(t)
+
+	password, err := enc.Encrypt(key, "pa$$")
+	require.NoError(t, err)
+
+	options := map[string]zfg.Option{"db.password": {Name: "db.password", Type: "string"}}
+	ctx := context.WithValue(context.Background(), ctxKey{}, "value")
+
+	// ctx reaches the wrapped provider
+	r := &ctxRecorder{Provider: static.New(map[string]any{"db.password": password})}
+	found, _, err := enc.New(r, enc.WithKey(key)).ProvideContext(ctx, options, zfg.ToString)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"db.password": "pa$$"}, found)
+	assert.Equal(t, "value", r.ctx.Value(ctxKey{}))
+
+	found, _, err = enc.New(static.New(map[string]any{"db.password": password}), enc.WithKey(key)).
+		ProvideContext(ctx, options, zfg.ToString)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"db.password": "pa$$"}, found)
+}
+
+type ctxKey struct{}
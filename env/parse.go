@@ -1,13 +1,24 @@
 package env
 
 import (
+	"encoding/json"
 	"os"
 	"regexp"
 	"strings"
+
+	"github.com/chaindead/zerocfg/meta"
 )
 
 var cleanRe = regexp.MustCompile(`[^A-Za-z0-9.]+`)
 
+// quotedTypes are slice option types whose elements are always JSON strings, e.g. "1" of strings.
+var quotedTypes = map[string]bool{
+	"strings":   true,
+	"durations": true,
+}
+
+const mapType = "map"
+
 type Opt func(*Provider)
 
 // WithPrefix returns an Opt that sets the prefix for environment variable names in the Provider.
@@ -17,10 +28,22 @@ func WithPrefix(prefix string) Opt {
 	}
 }
 
+// WithSeparator returns an Opt that enables list syntax for slice and map options.
+//
+// With WithSeparator(","), HOSTS=a,b,c sets a slice option and LIMITS=max=10,min=1 sets a map option.
+// Values starting with '[' or '{' are still parsed as JSON.
+func WithSeparator(sep string) Opt {
+	return func(p *Provider) {
+		p.sep = sep
+	}
+}
+
 // Provider parses environment variables for configuration.
 type Provider struct {
 	// Prefix to prepend to all environment variable names.
 	prefix string
+	// Separator of list items, list syntax is disabled if empty.
+	sep string
 }
 
 // New creates a new Provider with the provided options.
@@ -46,10 +69,20 @@ func (p Provider) key(s string) string {
 	return s
 }
 
-// Parse reads environment variables matching the awaited keys and returns found values.
+// Provide reads environment variables matching the awaited keys and returns found values as is,
+// option types of list syntax are only known to ProvideOptions.
 func (p Provider) Provide(awaited map[string]bool, _ func(any) string) (found, unknown map[string]string, err error) {
-	keys := make(map[string]string, len(awaited))
-	for k := range awaited {
+	return p.provide(meta.FromAwaited(awaited))
+}
+
+// ProvideOptions reads environment variables of options, list syntax is parsed for slice and map options (see WithSeparator).
+func (p Provider) ProvideOptions(options map[string]meta.Option, _ func(any) string) (found, unknown map[string]string, err error) {
+	return p.provide(options)
+}
+
+func (p Provider) provide(options map[string]meta.Option) (found, unknown map[string]string, err error) {
+	keys := make(map[string]string, len(options))
+	for k := range options {
 		keys[k] = toENV(p.key(k))
 	}
 
@@ -60,12 +93,87 @@ func (p Provider) Provide(awaited map[string]bool, _ func(any) string) (found, u
 			continue
 		}
 
-		found[original] = v
+		found[original] = p.format(options[original], v)
 	}
 
 	return found, unknown, nil
 }
 
+// format converts separated list syntax of slice and map options to JSON.
+func (p Provider) format(o meta.Option, v string) string {
+	if p.sep == "" || strings.HasPrefix(v, "[") || strings.HasPrefix(v, "{") {
+		return v
+	}
+
+	if o.Type == mapType {
+		return toMap(v, p.sep)
+	}
+
+	if o.IsSlice {
+		return toList(v, p.sep, quotedTypes[o.Type])
+	}
+
+	return v
+}
+
+// toList converts "a,b,c" to a JSON array. Items are quoted if quoted is set,
+// otherwise only items that are not JSON scalars (numbers, booleans, null) are quoted.
+func toList(v, sep string, quoted bool) string {
+	items := split(v, sep)
+
+	if quoted {
+		data, _ := json.Marshal(items)
+		return string(data)
+	}
+
+	raw := make([]json.RawMessage, 0, len(items))
+	for _, item := range items {
+		raw = append(raw, scalar(item))
+	}
+
+	data, _ := json.Marshal(raw)
+	return string(data)
+}
+
+// toMap converts "k1=v1,k2=v2" to a JSON object.
+// Values that are valid JSON scalars (numbers, booleans, null) are kept as is, others are quoted.
+func toMap(v, sep string) string {
+	m := make(map[string]json.RawMessage)
+	for _, item := range split(v, sep) {
+		k, val, _ := strings.Cut(item, "=")
+		m[strings.TrimSpace(k)] = scalar(strings.TrimSpace(val))
+	}
+
+	data, _ := json.Marshal(m)
+	return string(data)
+}
+
+func scalar(s string) json.RawMessage {
+	var v any
+	if err := json.Unmarshal([]byte(s), &v); err == nil {
+		switch v.(type) {
+		case float64, bool, nil:
+			return json.RawMessage(s)
+		}
+	}
+
+	data, _ := json.Marshal(s)
+	return data
+}
+
+func split(v, sep string) []string {
+	items := make([]string, 0)
+	if strings.TrimSpace(v) == "" {
+		return items
+	}
+
+	for _, item := range strings.Split(v, sep) {
+		items = append(items, strings.TrimSpace(item))
+	}
+
+	return items
+}
+
 // toENV transforms the input string into an uppercase, underscore-separated
 // environment variable name by:
 // 1. Removing all characters except letters, digits, and dots.
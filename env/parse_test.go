@@ -16,6 +16,7 @@ func TestParse(t *testing.T) {
 		envs    map[string]string
 		awaited map[string]bool
 		found   map[string]string
+		options map[string]zfg.Option
 		opts    []env.Opt
 	}{
 		{
@@ -59,6 +60,74 @@ func TestParse(t *testing.T) {
 			found:   map[string]string{"foo": "bar"},
 			opts:    []env.Opt{env.WithPrefix("prefix")},
 		},
+		{
+			name: "list",
+			envs: map[string]string{
+				"HOSTS": "a, b,c",
+				"PORTS": "1,2",
+				"NAME":  "x,y",
+			},
+			found: map[string]string{"hosts": `["a","b","c"]`, "ports": `[1,2]`, "name": "x,y"},
+			options: map[string]zfg.Option{
+				"hosts": {Name: "hosts", Type: "strings", IsSlice: true},
+				"ports": {Name: "ports", Type: "ints", IsSlice: true},
+				"name":  {Name: "name", Type: "string"},
+			},
+			opts: []env.Opt{env.WithSeparator(",")},
+		},
+		{
+			name: "custom list",
+			envs: map[string]string{
+				"LEVELS": "debug, 2,true",
+			},
+			found:   map[string]string{"levels": `["debug",2,true]`},
+			options: map[string]zfg.Option{"levels": {Name: "levels", Type: "levels", IsSlice: true}},
+			opts:    []env.Opt{env.WithSeparator(",")},
+		},
+		{
+			name: "list without options",
+			envs: map[string]string{
+				"HOSTS": "a,b",
+			},
+			awaited: map[string]bool{"hosts": true},
+			found:   map[string]string{"hosts": "a,b"},
+			opts:    []env.Opt{env.WithSeparator(",")},
+		},
+		{
+			name: "list json",
+			envs: map[string]string{
+				"HOSTS": `["a","b"]`,
+			},
+			found:   map[string]string{"hosts": `["a","b"]`},
+			options: map[string]zfg.Option{"hosts": {Name: "hosts", Type: "strings", IsSlice: true}},
+			opts:    []env.Opt{env.WithSeparator(",")},
+		},
+		{
+			name: "list disabled",
+			envs: map[string]string{
+				"HOSTS": "a,b",
+			},
+			found:   map[string]string{"hosts": "a,b"},
+			options: map[string]zfg.Option{"hosts": {Name: "hosts", Type: "strings", IsSlice: true}},
+		},
+		{
+			name: "list custom separator",
+			envs: map[string]string{
+				"DURS": "1s;2m",
+			},
+			found:   map[string]string{"durs": `["1s","2m"]`},
+			options: map[string]zfg.Option{"durs": {Name: "durs", Type: "durations", IsSlice: true}},
+			opts:    []env.Opt{env.WithSeparator(";")},
+		},
+		{
+			name: "map",
+			envs: map[string]string{
+				"LIMITS": "max=10,min=1,name=low,on=true",
+			},
+			found:   map[string]string{"limits": `{"max":10,"min":1,"name":"low","on":true}`},
+			options: map[string]zfg.Option{"limits": {Name: "limits", Type: "map"}},
+			opts:    []env.Opt{env.WithSeparator(",")},
+		},
 	}
 
 	for _, tt := range tests {
@@ -78,7 +147,13 @@ func TestParse(t *testing.T) {
 				require.NoError(t, err)
 			}
 
-			found, unknown, err := p.Provide(tt.awaited, zfg.ToString)
+			var found, unknown map[string]string
+			var err error
+			if tt.options != nil {
+				found, unknown, err = p.ProvideOptions(tt.options, zfg.ToString)
+			} else {
+				found, unknown, err = p.Provide(tt.awaited, zfg.ToString)
+			}
 			require.NoError(t, err)
 			assert.Empty(t, unknown)
 
@@ -87,3 +162,20 @@ func TestParse(t *testing.T) {
 		})
 	}
 }
+
+func TestProvideOptions(t *testing.T) {
+	t.Setenv("HOSTS", "a,b")
+	t.Setenv("H", "c")
+	t.Setenv("PORT", "1,2")
+
+	options := map[string]zfg.Option{
+		"hosts": {Name: "hosts", Type: "strings", IsSlice: true},
+		"h":     {Name: "hosts", Type: "strings", IsSlice: true, IsAlias: true},
+		"port":  {Name: "port", Type: "int"},
+	}
+
+	found, unknown, err := env.New(env.WithSeparator(",")).ProvideOptions(options, zfg.ToString)
+	require.NoError(t, err)
+	assert.Empty(t, unknown)
+	assert.Equal(t, map[string]string{"hosts": `["a","b"]`, "h": `["c"]`, "port": "1,2"}, found)
+}
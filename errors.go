@@ -24,10 +24,20 @@ var (
 
 	// ErrDoubleParse is returned when Parse is called more than once.
 	ErrDoubleParse = errors.New("misuse: Parse func should be called once")
+
+	// ErrResolvedValue is returned when a value resolved from a reference or masked by its provider (see Masker)
+	// is invalid for the option type.
+	// The value itself is not included in the error.
+	ErrResolvedValue = errors.New("invalid resolved value")
+
+	// ErrNoResolver is returned when a resolvable option holds a reference of a scheme
+	// with no registered resolver (e.g. "exec://" without ExecResolver, see RegisterResolver).
+	ErrNoResolver = errors.New("no resolver registered")
 )
 
 // UnknownFieldError represents a mapping from configuration source names to unknown option keys encountered during parsing.
 // It is returned by Parse when unknown values are found in configuration sources.
+// Keys are bare option names, locations of keys (see Locator) are only reported in the error message.
 type UnknownFieldError map[string][]string
 
 // IsUnknown checks if the provided error is an UnknownFieldError.
@@ -53,15 +63,60 @@ func (e UnknownFieldError) Error() string {
 	return fmt.Sprintf("unknown fields: %s", string(data))
 }
 
-func (e *UnknownFieldError) add(source string, unknown map[string]string) {
+// unknownFields collects unknown keys of providers and locations of the keys.
+type unknownFields struct {
+	keys      UnknownFieldError
+	locations map[string]map[string]string
+}
+
+func (u *unknownFields) add(source string, unknown map[string]string, locate func(string) string) {
 	if len(unknown) == 0 {
 		return
 	}
 
-	s := make([]string, 0, len(unknown))
 	for k := range unknown {
-		s = append(s, k)
+		u.keys[source] = append(u.keys[source], k)
+
+		if loc := locate(k); loc != "" {
+			if u.locations[source] == nil {
+				u.locations[source] = make(map[string]string)
+			}
+			u.locations[source][k] = loc
+		}
+	}
+}
+
+// err returns nil if no unknown keys are found.
+func (u *unknownFields) err() error {
+	if len(u.keys) == 0 {
+		return nil
+	}
+
+	if len(u.locations) == 0 {
+		return u.keys
 	}
 
-	(*e)[source] = s
+	return locatedUnknownError{UnknownFieldError: u.keys, locations: u.locations}
+}
+
+// locatedUnknownError is an UnknownFieldError whose message includes locations of keys,
+// e.g. "db.hots (prod.yaml:3:5)". IsUnknown returns the UnknownFieldError with bare keys.
+type locatedUnknownError struct {
+	UnknownFieldError
+	locations map[string]map[string]string
+}
+
+func (e locatedUnknownError) Error() string {
+	located := make(UnknownFieldError, len(e.UnknownFieldError))
+	for source, keys := range e.UnknownFieldError {
+		for _, k := range keys {
+			located[source] = append(located[source], k+e.locations[source][k])
+		}
+	}
+
+	return located.Error()
+}
+
+func (e locatedUnknownError) Unwrap() error {
+	return e.UnknownFieldError
 }
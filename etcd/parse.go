@@ -0,0 +1,372 @@
+package etcd
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"reflect"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/chaindead/zerocfg/meta"
+	"github.com/chaindead/zerocfg/util"
+)
+
+const (
+	defaultTimeout = 10 * time.Second
+	retryInterval  = 5 * time.Second
+)
+
+var errStatus = errors.New("unexpected status")
+
+type Opt func(*Provider)
+
+// WithAuth returns an Opt that authenticates with a user and password before requests.
+func WithAuth(user, password string) Opt {
+	return func(p *Provider) {
+		p.user, p.password = user, password
+	}
+}
+
+// WithTimeout returns an Opt that limits the duration of authentication and range requests, 10 seconds by default.
+// Watch streams are not limited.
+func WithTimeout(d time.Duration) Opt {
+	return func(p *Provider) {
+		p.timeout = d
+	}
+}
+
+// WithClient returns an Opt that sets the http client of the gateway, e.g. with client certificates of the cluster.
+func WithClient(client *http.Client) Opt {
+	return func(p *Provider) {
+		p.client = client
+	}
+}
+
+// Provider reads a key range of etcd v3 via the gRPC gateway JSON API.
+type Provider struct {
+	addr     *string
+	prefix   *string
+	user     string
+	password string
+	timeout  time.Duration
+	client   *http.Client
+
+	mu       sync.Mutex
+	revision int64
+	last     map[string]string
+	keys     map[string]string // option key -> etcd key
+}
+
+type keyValue struct {
+	Key   []byte `json:"key"`   // base64 in json
+	Value []byte `json:"value"` // base64 in json
+}
+
+type header struct {
+	Revision int64 `json:"revision,string"`
+}
+
+type rangeRequest struct {
+	Key      []byte `json:"key"`
+	RangeEnd []byte `json:"range_end"`
+}
+
+type rangeResponse struct {
+	Header header     `json:"header"`
+	Kvs    []keyValue `json:"kvs"`
+}
+
+type watchRequest struct {
+	CreateRequest struct {
+		rangeRequest
+		StartRevision int64 `json:"start_revision,string"`
+	} `json:"create_request"`
+}
+
+type watchResponse struct {
+	Result struct {
+		Header   header            `json:"header"`
+		Events   []json.RawMessage `json:"events"`
+		Canceled bool              `json:"canceled"`
+		Reason   string            `json:"cancel_reason"`
+	} `json:"result"`
+	Error *struct {
+		Message string `json:"message"`
+	} `json:"error"`
+}
+
+// New creates a Provider reading all keys under prefix from the etcd gateway at addr (e.g. "http://127.0.0.1:2379").
+//
+// Key paths relative to prefix map to option names with slashes replaced by dots,
+// so "/app/db/host" with prefix "/app" sets option "db.host".
+func New(addr, prefix *string, opts ...Opt) *Provider {
+	p := &Provider{
+		addr:    addr,
+		prefix:  prefix,
+		timeout: defaultTimeout,
+		client:  http.DefaultClient,
+	}
+
+	for _, opt := range opts {
+		opt(p)
+	}
+
+	return p
+}
+
+func (p *Provider) Type() string {
+	return fmt.Sprintf("etcd[%s]", p.root())
+}
+
+// Locate returns the etcd key of an option.
+func (p *Provider) Locate(key string) (string, bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	k, ok := p.keys[key]
+	return k, ok
+}
+
+func (p *Provider) Provide(keys map[string]bool, conv func(any) string) (found, unknown map[string]string, err error) {
+	return p.provide(context.Background(), keys, conv)
+}
+
+// ProvideContext authenticates (see WithAuth) and reads the key range with ctx.
+func (p *Provider) ProvideContext(ctx context.Context, options map[string]meta.Option, conv func(any) string) (found, unknown map[string]string, err error) {
+	return p.provide(ctx, meta.Awaited(options), conv)
+}
+
+func (p *Provider) provide(ctx context.Context, keys map[string]bool, conv func(any) string) (found, unknown map[string]string, err error) {
+	settings, err := p.fetch(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	found, unknown = util.Split(settings, keys, conv)
+
+	return found, unknown, nil
+}
+
+// Watch watches the key range and calls onChange when any key changes, until ctx is done.
+// Broken watch streams are reopened after a delay, changes made meanwhile are not missed.
+func (p *Provider) Watch(ctx context.Context, onChange func()) error {
+	for {
+		err := p.watch(ctx, onChange)
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+
+		if err != nil {
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-time.After(retryInterval):
+			}
+		}
+	}
+}
+
+// watch reads a watch stream and fetches the range on every change.
+func (p *Provider) watch(ctx context.Context, onChange func()) error {
+	p.mu.Lock()
+	revision := p.revision
+	p.mu.Unlock()
+
+	var body watchRequest
+	body.CreateRequest.rangeRequest = p.rangeRequest()
+	body.CreateRequest.StartRevision = revision + 1
+
+	resp, err := p.post(ctx, "/v3/watch", body)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	dec := json.NewDecoder(resp.Body)
+	for {
+		var wr watchResponse
+		if err := dec.Decode(&wr); err != nil {
+			return fmt.Errorf("decode watch response: %w", err)
+		}
+
+		if wr.Error != nil {
+			return fmt.Errorf("watch: %s", wr.Error.Message)
+		}
+		if wr.Result.Canceled {
+			return fmt.Errorf("watch canceled: %s", wr.Result.Reason)
+		}
+		if len(wr.Result.Events) == 0 {
+			continue
+		}
+
+		p.mu.Lock()
+		last := p.last
+		p.mu.Unlock()
+
+		settings, err := p.fetch(ctx)
+		if err != nil {
+			return err
+		}
+
+		if !reflect.DeepEqual(settings, last) {
+			onChange()
+		}
+	}
+}
+
+// fetch reads the key range and remembers its revision.
+func (p *Provider) fetch(ctx context.Context) (map[string]string, error) {
+	ctx, cancel := context.WithTimeout(ctx, p.timeout)
+	defer cancel()
+
+	resp, err := p.post(ctx, "/v3/kv/range", p.rangeRequest())
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	var rr rangeResponse
+	if err := json.NewDecoder(resp.Body).Decode(&rr); err != nil {
+		return nil, fmt.Errorf("decode range response: %w", err)
+	}
+
+	settings := make(map[string]string)
+	keys := make(map[string]string)
+	for _, kv := range rr.Kvs {
+		key, ok := p.key(string(kv.Key))
+		if !ok {
+			continue
+		}
+
+		settings[key] = string(kv.Value)
+		keys[key] = string(kv.Key)
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.last = settings
+	p.keys = keys
+	p.revision = rr.Header.Revision
+
+	return settings, nil
+}
+
+func (p *Provider) post(ctx context.Context, path string, body any) (*http.Response, error) {
+	var token string
+	if p.user != "" {
+		var err error
+		if token, err = p.authenticate(ctx); err != nil {
+			return nil, err
+		}
+	}
+
+	resp, err := p.do(ctx, path, token, body)
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		resp.Body.Close()
+		return nil, fmt.Errorf("%w: %s", errStatus, resp.Status)
+	}
+
+	return resp, nil
+}
+
+func (p *Provider) authenticate(ctx context.Context) (string, error) {
+	resp, err := p.do(ctx, "/v3/auth/authenticate", "", map[string]string{"name": p.user, "password": p.password})
+	if err != nil {
+		return "", fmt.Errorf("authenticate: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("authenticate: %w: %s", errStatus, resp.Status)
+	}
+
+	var auth struct {
+		Token string `json:"token"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&auth); err != nil {
+		return "", fmt.Errorf("authenticate: decode response: %w", err)
+	}
+
+	return auth.Token, nil
+}
+
+func (p *Provider) do(ctx context.Context, path, token string, body any) (*http.Response, error) {
+	data, err := json.Marshal(body)
+	if err != nil {
+		return nil, fmt.Errorf("encode request: %w", err)
+	}
+
+	u := strings.TrimRight(*p.addr, "/") + path
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, u, bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if token != "" {
+		req.Header.Set("Authorization", token)
+	}
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("fetch: %w", err)
+	}
+
+	return resp, nil
+}
+
+// rangeRequest returns the range of all keys with the prefix, or of all keys for an empty prefix.
+func (p *Provider) rangeRequest() rangeRequest {
+	root := p.root()
+	if root == "" {
+		return rangeRequest{Key: []byte{0}, RangeEnd: []byte{0}}
+	}
+
+	return rangeRequest{Key: []byte(root), RangeEnd: prefixEnd([]byte(root))}
+}
+
+// root returns the prefix as a folder path ("/app/") or an empty string for the whole keyspace.
+func (p *Provider) root() string {
+	prefix := strings.TrimRight(*p.prefix, "/")
+	if prefix == "" {
+		return ""
+	}
+
+	return prefix + "/"
+}
+
+// key maps an etcd key to an option name, keys outside of the prefix and folder-like keys are skipped.
+func (p *Provider) key(etcdKey string) (string, bool) {
+	rest, ok := strings.CutPrefix(etcdKey, p.root())
+	rest = strings.TrimLeft(rest, "/")
+	if !ok || rest == "" || strings.HasSuffix(rest, "/") {
+		return "", false
+	}
+
+	return strings.ReplaceAll(rest, "/", "."), true
+}
+
+// prefixEnd returns the end of the range of keys starting with prefix, as clientv3.GetPrefixRangeEnd.
+func prefixEnd(prefix []byte) []byte {
+	end := make([]byte, len(prefix))
+	copy(end, prefix)
+
+	for i := len(end) - 1; i >= 0; i-- {
+		if end[i] < 0xff {
+			end[i]++
+			return end[:i+1]
+		}
+	}
+
+	// all bytes are 0xff, range to the end of the keyspace
+	return []byte{0}
+}
@@ -0,0 +1,293 @@
+package etcd_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	zfg "github.com/chaindead/zerocfg"
+	"github.com/chaindead/zerocfg/etcd"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeEtcd implements the subset of the etcd gRPC gateway API used by the provider.
+type fakeEtcd struct {
+	mu       sync.Mutex
+	changed  chan string
+	revision int64
+	kv       map[string]string
+	user     string
+	password string
+}
+
+type kv struct {
+	Key   []byte `json:"key"`
+	Value []byte `json:"value"`
+}
+
+type rng struct {
+	Key      []byte `json:"key"`
+	RangeEnd []byte `json:"range_end"`
+}
+
+func (r rng) contains(key string) bool {
+	switch {
+	case string(r.RangeEnd) == "\x00":
+		return key >= string(r.Key)
+	case len(r.RangeEnd) == 0:
+		return key == string(r.Key)
+	default:
+		return key >= string(r.Key) && key < string(r.RangeEnd)
+	}
+}
+
+func newFake(kv map[string]string) *fakeEtcd {
+	return &fakeEtcd{kv: kv, revision: 1, changed: make(chan string, 10)}
+}
+
+func (f *fakeEtcd) put(key, value string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.kv[key] = value
+	f.revision++
+	f.changed <- key
+}
+
+func (f *fakeEtcd) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Path == "/v3/auth/authenticate" {
+		var req struct{ Name, Password string }
+		_ = json.NewDecoder(r.Body).Decode(&req)
+		if req.Name != f.user || req.Password != f.password {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+
+		_, _ = w.Write([]byte(`{"token":"token"}`))
+		return
+	}
+
+	if f.user != "" && r.Header.Get("Authorization") != "token" {
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+
+	switch r.URL.Path {
+	case "/v3/kv/range":
+		var req rng
+		_ = json.NewDecoder(r.Body).Decode(&req)
+		f.serveRange(w, req)
+	case "/v3/watch":
+		var req struct {
+			CreateRequest rng `json:"create_request"`
+		}
+		_ = json.NewDecoder(r.Body).Decode(&req)
+		f.serveWatch(w, r, req.CreateRequest)
+	default:
+		w.WriteHeader(http.StatusNotFound)
+	}
+}
+
+func (f *fakeEtcd) serveRange(w http.ResponseWriter, req rng) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	var kvs []kv
+	for k, v := range f.kv {
+		if req.contains(k) {
+			kvs = append(kvs, kv{Key: []byte(k), Value: []byte(v)})
+		}
+	}
+
+	_ = json.NewEncoder(w).Encode(map[string]any{
+		"header": map[string]string{"revision": strconv.FormatInt(f.revision, 10)},
+		"kvs":    kvs,
+	})
+}
+
+func (f *fakeEtcd) serveWatch(w http.ResponseWriter, r *http.Request, req rng) {
+	enc := json.NewEncoder(w)
+	_ = enc.Encode(map[string]any{"result": map[string]any{"created": true}})
+	w.(http.Flusher).Flush()
+
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case key := <-f.changed:
+			if !req.contains(key) {
+				continue
+			}
+
+			event := map[string]any{"type": "PUT", "kv": kv{Key: []byte(key)}}
+			_ = enc.Encode(map[string]any{"result": map[string]any{"events": []any{event}}})
+			w.(http.Flusher).Flush()
+		}
+	}
+}
+
+func TestProvide(t *testing.T) {
+	tests := []struct {
+		name    string
+		prefix  string
+		kv      map[string]string
+		awaited map[string]bool
+		found   map[string]string
+		unknown map[string]string
+	}{
+		{
+			name:   "nested keys",
+			prefix: "/app",
+			kv: map[string]string{
+				"/app/db/host":    "localhost",
+				"/app/db/port":    "5432",
+				"/application/db": "other",
+				"/other":          "other",
+			},
+			awaited: map[string]bool{"db.host": true},
+			found:   map[string]string{"db.host": "localhost"},
+			unknown: map[string]string{"db.port": "5432"},
+		},
+		{
+			name:    "trailing slash",
+			prefix:  "app/",
+			kv:      map[string]string{"app/name": "svc"},
+			awaited: map[string]bool{"name": true},
+			found:   map[string]string{"name": "svc"},
+		},
+		{
+			name:    "whole keyspace",
+			kv:      map[string]string{"/db/host": "localhost"},
+			awaited: map[string]bool{"db.host": true},
+			found:   map[string]string{"db.host": "localhost"},
+		},
+		{
+			name:    "json value",
+			prefix:  "/app",
+			kv:      map[string]string{"/app/hosts": `["a","b"]`},
+			awaited: map[string]bool{"hosts": true},
+			found:   map[string]string{"hosts": `["a","b"]`},
+		},
+		{
+			name:   "empty range",
+			prefix: "/missing",
+			kv:     map[string]string{"/app/db/host": "localhost"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.found == nil {
+				tt.found = map[string]string{}
+			}
+			if tt.unknown == nil {
+				tt.unknown = map[string]string{}
+			}
+
+			srv := httptest.NewServer(newFake(tt.kv))
+			defer srv.Close()
+
+			p := etcd.New(&srv.URL, &tt.prefix)
+
+			found, unknown, err := p.Provide(tt.awaited, zfg.ToString)
+			require.NoError(t, err)
+			assert.Equal(t, tt.found, found)
+			assert.Equal(t, tt.unknown, unknown)
+		})
+	}
+}
+
+func TestAuth(t *testing.T) {
+	fake := newFake(map[string]string{"/app/db/host": "localhost"})
+	fake.user, fake.password = "root", "secret"
+
+	srv := httptest.NewServer(fake)
+	defer srv.Close()
+
+	prefix := "/app"
+
+	_, _, err := etcd.New(&srv.URL, &prefix, etcd.WithAuth("root", "wrong")).Provide(map[string]bool{}, zfg.ToString)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "authenticate: unexpected status: 401 Unauthorized")
+
+	found, _, err := etcd.New(&srv.URL, &prefix, etcd.WithAuth("root", "secret")).
+		Provide(map[string]bool{"db.host": true}, zfg.ToString)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"db.host": "localhost"}, found)
+}
+
+func TestLocate(t *testing.T) {
+	srv := httptest.NewServer(newFake(map[string]string{"/app/db/host": "localhost"}))
+	defer srv.Close()
+
+	prefix := "/app"
+	p := etcd.New(&srv.URL, &prefix)
+
+	_, _, err := p.Provide(map[string]bool{}, zfg.ToString)
+	require.NoError(t, err)
+
+	loc, ok := p.Locate("db.host")
+	require.True(t, ok)
+	assert.Equal(t, "/app/db/host", loc)
+	assert.Equal(t, "etcd[/app/]", p.Type())
+}
+
+func TestProvideContext(t *testing.T) {
+	srv := httptest.NewServer(newFake(map[string]string{"/app/db/host": "localhost"}))
+	defer srv.Close()
+
+	prefix := "/app"
+	options := map[string]zfg.Option{"db.host": {Name: "db.host", Type: "string"}}
+
+	found, _, err := etcd.New(&srv.URL, &prefix).ProvideContext(context.Background(), options, zfg.ToString)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"db.host": "localhost"}, found)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, _, err = etcd.New(&srv.URL, &prefix).ProvideContext(ctx, options, zfg.ToString)
+	require.ErrorIs(t, err, context.Canceled)
+}
+
+func TestWatch(t *testing.T) {
+	fake := newFake(map[string]string{"/app/db/host": "localhost"})
+	srv := httptest.NewServer(fake)
+	defer srv.Close()
+
+	prefix := "/app"
+	p := etcd.New(&srv.URL, &prefix)
+
+	_, _, err := p.Provide(map[string]bool{}, zfg.ToString)
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	changed := make(chan struct{}, 1)
+	go func() {
+		_ = p.Watch(ctx, func() { changed <- struct{}{} })
+	}()
+
+	// key outside of the range is not watched
+	fake.put("/other", "value")
+
+	select {
+	case <-changed:
+		t.Fatal("unexpected change")
+	case <-time.After(100 * time.Millisecond):
+	}
+
+	fake.put("/app/db/host", "db.local")
+
+	select {
+	case <-changed:
+	case <-ctx.Done():
+		t.Fatal("change not detected")
+	}
+}
@@ -3,6 +3,8 @@ package flag
 import (
 	"os"
 	"strings"
+
+	"github.com/chaindead/zerocfg/meta"
 )
 
 type Provider struct{}
@@ -18,11 +20,25 @@ func (Provider) Type() string {
 func (Provider) Provide(awaited map[string]bool, _ func(any) string) (found, unknown map[string]string, err error) {
 	args := os.Args[1:]
 
-	found, unknown = parse(awaited, args)
+	found, unknown = parse(awaited, nil, args)
 	return
 }
 
-func parse(awaited map[string]bool, args []string) (found, unknown map[string]string) {
+// ProvideOptions parses command-line arguments as Provide, but boolean flags take the next argument
+// only if it is a boolean value, so "-debug file.txt" sets debug to true.
+func (Provider) ProvideOptions(options map[string]meta.Option, _ func(any) string) (found, unknown map[string]string, err error) {
+	args := os.Args[1:]
+
+	bools := make(map[string]bool)
+	for k, o := range options {
+		bools[k] = o.IsBool
+	}
+
+	found, unknown = parse(meta.Awaited(options), bools, args)
+	return
+}
+
+func parse(awaited, bools map[string]bool, args []string) (found, unknown map[string]string) {
 	found, unknown = make(map[string]string), make(map[string]string)
 
 	for i := 0; i < len(args); i++ {
@@ -42,7 +58,7 @@ func parse(awaited map[string]bool, args []string) (found, unknown map[string]st
 		}
 
 		var value string
-		if i+1 < len(args) && len(args[i+1]) > 0 && args[i+1][0] != '-' {
+		if i+1 < len(args) && len(args[i+1]) > 0 && args[i+1][0] != '-' && (!bools[name] || isBool(args[i+1])) {
 			value = args[i+1]
 			i++
 		}
@@ -56,3 +72,12 @@ func parse(awaited map[string]bool, args []string) (found, unknown map[string]st
 
 	return
 }
+
+func isBool(s string) bool {
+	switch strings.ToLower(s) {
+	case "true", "false", "1", "0", "yes", "no":
+		return true
+	default:
+		return false
+	}
+}
@@ -100,3 +100,44 @@ func TestParse(t *testing.T) {
 		})
 	}
 }
+
+func TestProvideOptions(t *testing.T) {
+	tests := []struct {
+		name  string
+		args  []string
+		found map[string]string
+	}{
+		{
+			name:  "bool before positional",
+			args:  []string{"-debug", "file.txt", "--name", "x"},
+			found: map[string]string{"debug": "", "name": "x"},
+		},
+		{
+			name:  "bool with value",
+			args:  []string{"--debug", "false", "-d", "yes"},
+			found: map[string]string{"debug": "false", "d": "yes"},
+		},
+		{
+			name:  "alias of bool",
+			args:  []string{"-d", "file.txt"},
+			found: map[string]string{"d": ""},
+		},
+	}
+
+	options := map[string]zfg.Option{
+		"debug": {Name: "debug", Type: "bool", IsBool: true},
+		"d":     {Name: "debug", Type: "bool", IsBool: true, IsAlias: true},
+		"name":  {Name: "name", Type: "string"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			os.Args = append([]string{"program"}, tt.args...)
+
+			found, unknown, err := flag.New().ProvideOptions(options, zfg.ToString)
+			require.NoError(t, err)
+			assert.Empty(t, unknown)
+			assert.Equal(t, tt.found, found)
+		})
+	}
+}
@@ -0,0 +1,160 @@
+package ini
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"strconv"
+	"strings"
+
+	"github.com/chaindead/zerocfg/util"
+)
+
+var errSyntax = errors.New("syntax error")
+
+type Opt func(*Provider)
+
+// WithFS returns an Opt that reads the file from fsys instead of the operating system,
+// e.g. from an embed.FS with default configs. The path is slash-separated and relative to the root of fsys.
+func WithFS(fsys fs.FS) Opt {
+	return func(p *Provider) {
+		p.fsys = fsys
+	}
+}
+
+type Provider struct {
+	path *string
+	fsys fs.FS
+
+	lines map[string]int
+}
+
+// New creates a Provider reading an ini file.
+//
+// Sections become key prefixes, so "host" in section "[db]" sets option "db.host".
+// Lines starting with ';' or '#' are comments, a trailing '\' continues a value on the next line
+// and double-quoted values support Go escape sequences.
+func New(path *string, opts ...Opt) *Provider {
+	p := &Provider{path: path}
+	for _, opt := range opts {
+		opt(p)
+	}
+
+	return p
+}
+
+func (p *Provider) Type() string {
+	return fmt.Sprintf("ini[%s]", util.ShortenPath(*p.path))
+}
+
+// Locate returns the position of a key as "file:line".
+func (p *Provider) Locate(key string) (string, bool) {
+	line, ok := p.lines[key]
+	if !ok {
+		return "", false
+	}
+
+	return fmt.Sprintf("%s:%d", *p.path, line), true
+}
+
+func (p *Provider) Provide(keys map[string]bool, conv func(any) string) (found, unknown map[string]string, err error) {
+	data, err := util.ReadFile(p.fsys, *p.path)
+	if err != nil {
+		return nil, nil, fmt.Errorf("read ini file: %w", err)
+	}
+
+	settings, lines, err := parse(string(data))
+	if err != nil {
+		return nil, nil, fmt.Errorf("unmarshal ini: %w", err)
+	}
+	p.lines = lines
+
+	found, unknown = util.Split(settings, keys, conv)
+
+	return found, unknown, nil
+}
+
+func parse(data string) (settings map[string]string, lines map[string]int, err error) {
+	settings, lines = make(map[string]string), make(map[string]int)
+
+	var section string
+	rows := strings.Split(strings.ReplaceAll(data, "\r\n", "\n"), "\n")
+	for i := 0; i < len(rows); i++ {
+		n := i + 1
+		row := strings.TrimSpace(rows[i])
+		if row == "" || row[0] == ';' || row[0] == '#' {
+			continue
+		}
+
+		// a trailing backslash continues the row on the next one
+		for strings.HasSuffix(row, `\`) && i+1 < len(rows) {
+			i++
+			row = strings.TrimSuffix(row, `\`) + strings.TrimSpace(rows[i])
+		}
+
+		// continuation of a lone backslash with an empty row
+		if row == "" {
+			continue
+		}
+
+		if row[0] == '[' {
+			if !strings.HasSuffix(row, "]") {
+				return nil, nil, fmt.Errorf("line %d: %w: unterminated section %q", n, errSyntax, row)
+			}
+
+			section = strings.TrimSpace(row[1 : len(row)-1])
+			if section == "" {
+				return nil, nil, fmt.Errorf("line %d: %w: empty section name", n, errSyntax)
+			}
+
+			continue
+		}
+
+		k, v, ok := strings.Cut(row, "=")
+		if ck, cv, cok := strings.Cut(row, ":"); cok && (!ok || len(ck) < len(k)) {
+			k, v, ok = ck, cv, cok
+		}
+		if !ok {
+			return nil, nil, fmt.Errorf("line %d: %w: expected key = value, found %q", n, errSyntax, row)
+		}
+
+		k = strings.TrimSpace(k)
+		if k == "" {
+			return nil, nil, fmt.Errorf("line %d: %w: empty key", n, errSyntax)
+		}
+		if section != "" {
+			k = section + "." + k
+		}
+
+		v, err = value(strings.TrimSpace(v))
+		if err != nil {
+			return nil, nil, fmt.Errorf("line %d: %w: %s", n, errSyntax, err)
+		}
+
+		settings[k] = v
+		lines[k] = n
+	}
+
+	return settings, lines, nil
+}
+
+// value unquotes double-quoted values with escape sequences and single-quoted raw values.
+func value(v string) (string, error) {
+	if len(v) < 2 {
+		return v, nil
+	}
+
+	switch {
+	case v[0] == '"' && v[len(v)-1] == '"':
+		u, err := strconv.Unquote(v)
+		if err != nil {
+			return "", fmt.Errorf("invalid quoted value %s", v)
+		}
+
+		return u, nil
+	case v[0] == '\'' && v[len(v)-1] == '\'':
+		return v[1 : len(v)-1], nil
+	}
+
+	return v, nil
+}
@@ -0,0 +1,156 @@
+package ini_test
+
+import (
+	"os"
+	"testing"
+	"testing/fstest"
+
+	zfg "github.com/chaindead/zerocfg"
+	"github.com/chaindead/zerocfg/ini"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		awaited map[string]bool
+		found   map[string]string
+		unknown map[string]string
+	}{
+		{
+			name: "sections",
+			input: `
+name = app
+
+[db]
+host = localhost
+port: 5432
+
+[db.replica]
+host=replica`,
+			awaited: map[string]bool{"db.host": true, "db.port": true, "db.replica.host": true},
+			found: map[string]string{
+				"db.host":         "localhost",
+				"db.port":         "5432",
+				"db.replica.host": "replica",
+			},
+			unknown: map[string]string{"name": "app"},
+		},
+		{
+			name: "comments",
+			input: `
+; comment
+# comment
+[a]
+; key = value
+key = value # not a comment`,
+			awaited: map[string]bool{"a.key": true},
+			found:   map[string]string{"a.key": "value # not a comment"},
+		},
+		{
+			name: "continuation",
+			input: `
+hosts = a,\
+        b,\
+        c`,
+			awaited: map[string]bool{"hosts": true},
+			found:   map[string]string{"hosts": "a,b,c"},
+		},
+		{
+			name:    "empty continuation",
+			input:   "\\ \n",
+			awaited: map[string]bool{},
+			found:   map[string]string{},
+		},
+		{
+			name: "quotes and escapes",
+			input: `
+quoted = "  tab\there\n \"q\" \u00e9"
+raw = '  C:\path'
+url = http://host:80/?a=b`,
+			awaited: map[string]bool{"quoted": true, "raw": true, "url": true},
+			found: map[string]string{
+				"quoted": "  tab\there\n \"q\" é",
+				"raw":    `  C:\path`,
+				"url":    "http://host:80/?a=b",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.unknown == nil {
+				tt.unknown = map[string]string{}
+			}
+
+			name := tempFile(t, tt.input)
+			p := ini.New(&name)
+
+			found, unknown, err := p.Provide(tt.awaited, zfg.ToString)
+			require.NoError(t, err)
+
+			assert.Equal(t, tt.found, found)
+			assert.Equal(t, tt.unknown, unknown)
+		})
+	}
+}
+
+func TestParse_Error(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		err   string
+	}{
+		{name: "no value", input: "[a]\nkey", err: `line 2: syntax error: expected key = value, found "key"`},
+		{name: "unterminated section", input: "[a", err: `line 1: syntax error: unterminated section "[a"`},
+		{name: "empty key", input: "\n= 1", err: "line 2: syntax error: empty key"},
+		{name: "bad quotes", input: `a = "\q"`, err: `line 1: syntax error: invalid quoted value "\q"`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			name := tempFile(t, tt.input)
+
+			_, _, err := ini.New(&name).Provide(map[string]bool{}, zfg.ToString)
+			require.ErrorContains(t, err, tt.err)
+		})
+	}
+}
+
+func TestLocate(t *testing.T) {
+	name := tempFile(t, "a = 1\n\n[db]\nport = 5432")
+	p := ini.New(&name)
+
+	_, _, err := p.Provide(map[string]bool{}, zfg.ToString)
+	require.NoError(t, err)
+
+	loc, ok := p.Locate("db.port")
+	assert.True(t, ok)
+	assert.Equal(t, name+":4", loc)
+}
+
+func TestFS(t *testing.T) {
+	fsys := fstest.MapFS{
+		"config/default.ini": {Data: []byte("[db]\nhost = localhost")},
+	}
+
+	path := "config/default.ini"
+	found, _, err := ini.New(&path, ini.WithFS(fsys)).Provide(map[string]bool{"db.host": true}, zfg.ToString)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"db.host": "localhost"}, found)
+}
+
+func tempFile(t *testing.T, data string) string {
+	f, err := os.CreateTemp("", "tmpfile-")
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		require.NoError(t, f.Close())
+	})
+
+	_, err = f.WriteString(data)
+	require.NoError(t, err)
+
+	return f.Name()
+}
@@ -0,0 +1,129 @@
+package json
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+
+	"github.com/chaindead/zerocfg/util"
+)
+
+type Opt func(*Provider)
+
+// WithFS returns an Opt that reads the file from fsys instead of the operating system,
+// e.g. from an embed.FS with default configs. The path is slash-separated and relative to the root of fsys.
+func WithFS(fsys fs.FS) Opt {
+	return func(p *Provider) {
+		p.fsys = fsys
+	}
+}
+
+type Provider struct {
+	path *string
+	fsys fs.FS
+}
+
+// New creates a Provider reading a json file, nested objects are mapped to dotted option names.
+func New(path *string, opts ...Opt) *Provider {
+	p := &Provider{path: path}
+	for _, opt := range opts {
+		opt(p)
+	}
+
+	return p
+}
+
+func (p *Provider) Type() string {
+	return fmt.Sprintf("json[%s]", util.ShortenPath(*p.path))
+}
+
+func (p *Provider) Provide(keys map[string]bool, conv func(any) string) (found, unknown map[string]string, err error) {
+	data, err := util.ReadFile(p.fsys, *p.path)
+	if err != nil {
+		return nil, nil, fmt.Errorf("read json file: %w", err)
+	}
+
+	settings, err := Decode(data)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	found, unknown = util.Flatten(settings, keys, conv)
+
+	return found, unknown, nil
+}
+
+// Decode parses a json document into nested settings, as used by Provider.
+func Decode(data []byte) (map[string]any, error) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+
+	var settings map[string]any
+	if err := dec.Decode(&settings); err != nil {
+		return nil, fmt.Errorf("unmarshal json: %w", withOffset(data, err))
+	}
+
+	if _, err := dec.Token(); !errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("unmarshal json: %s: unexpected data after top-level object", position(data, dec.InputOffset()))
+	}
+
+	rawNumbers(settings)
+
+	return settings, nil
+}
+
+// array is rendered as json, otherwise conv renders json.Number elements (being fmt.Stringer) as strings.
+type array []any
+
+func (a array) String() string {
+	data, _ := json.Marshal([]any(a))
+	return string(data)
+}
+
+func rawNumbers(m map[string]any) {
+	for k, v := range m {
+		switch v := v.(type) {
+		case map[string]any:
+			rawNumbers(v)
+		case []any:
+			m[k] = array(v)
+		}
+	}
+}
+
+// withOffset adds the line and column of syntax and type errors.
+func withOffset(data []byte, err error) error {
+	var (
+		syntaxErr *json.SyntaxError
+		typeErr   *json.UnmarshalTypeError
+	)
+
+	switch {
+	case errors.As(err, &syntaxErr):
+		return fmt.Errorf("%s: %w", position(data, syntaxErr.Offset), err)
+	case errors.As(err, &typeErr):
+		return fmt.Errorf("%s: %w", position(data, typeErr.Offset), err)
+	}
+
+	return err
+}
+
+func position(data []byte, offset int64) string {
+	if offset > int64(len(data)) {
+		offset = int64(len(data))
+	}
+
+	// offset points after the last read byte
+	if offset > 0 {
+		offset--
+	}
+
+	before := data[:offset]
+	line := bytes.Count(before, []byte("\n")) + 1
+	col := len(before) - bytes.LastIndexByte(before, '\n')
+
+	return fmt.Sprintf("offset %d (line %d, column %d)", offset, line, col)
+}
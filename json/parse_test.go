@@ -0,0 +1,140 @@
+package json_test
+
+import (
+	"os"
+	"testing"
+	"testing/fstest"
+
+	zfg "github.com/chaindead/zerocfg"
+	"github.com/chaindead/zerocfg/json"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		awaited map[string]bool
+		found   map[string]string
+		unknown map[string]string
+	}{
+		{
+			name:    "simple key-value",
+			input:   `{"str": "name", "int": 1}`,
+			awaited: map[string]bool{"str": true},
+			found:   map[string]string{"str": "name"},
+			unknown: map[string]string{"int": "1"},
+		},
+		{
+			name: "nested",
+			input: `{
+  "host": "localhost",
+  "database": {"port": 5432, "credentials": {"username": "admin"}}
+}`,
+			awaited: map[string]bool{"database.credentials.username": true},
+			found:   map[string]string{"database.credentials.username": "admin"},
+			unknown: map[string]string{
+				"host":          "localhost",
+				"database.port": "5432",
+			},
+		},
+		{
+			name:    "precision",
+			input:   `{"big": 12345678901234567890, "pi": 3.14159265358979323846}`,
+			awaited: map[string]bool{"big": true, "pi": true},
+			found:   map[string]string{"big": "12345678901234567890", "pi": "3.14159265358979323846"},
+		},
+		{
+			name:    "array",
+			input:   `{"tags": ["a", "b"], "ids": [1, 2.5], "nested": [[1], {"k": 1}]}`,
+			awaited: map[string]bool{"tags": true, "ids": true, "nested": true},
+			found: map[string]string{
+				"tags":   `["a","b"]`,
+				"ids":    `[1,2.5]`,
+				"nested": `[[1],{"k":1}]`,
+			},
+		},
+		{
+			name:    "null",
+			input:   `{"db": {"host": null, "port": 5432}, "stray": null}`,
+			awaited: map[string]bool{"db.host": true, "db.port": true},
+			found:   map[string]string{"db.port": "5432"},
+		},
+		{
+			name:    "map",
+			input:   `{"limits": {"max": 10, "min": 1}}`,
+			awaited: map[string]bool{"limits": true},
+			found:   map[string]string{"limits": `{"max":10,"min":1}`},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.found == nil {
+				tt.found = map[string]string{}
+			}
+			if tt.unknown == nil {
+				tt.unknown = map[string]string{}
+			}
+
+			name := tempFile(t, tt.input)
+			p := json.New(&name)
+
+			found, unknown, err := p.Provide(tt.awaited, zfg.ToString)
+			require.NoError(t, err)
+
+			assert.Equal(t, tt.found, found)
+			assert.Equal(t, tt.unknown, unknown)
+		})
+	}
+}
+
+func TestParse_Error(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		err   string
+	}{
+		{name: "syntax", input: "{\n  \"a\": 1,\n  \"b\" 2\n}", err: "offset 18 (line 3, column 7)"},
+		{name: "not object", input: `[1, 2]`, err: "offset 0 (line 1, column 1)"},
+		{name: "trailing data", input: `{"a": 1} {}`, err: "unexpected data after top-level object"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			name := tempFile(t, tt.input)
+
+			_, _, err := json.New(&name).Provide(map[string]bool{}, zfg.ToString)
+			require.ErrorContains(t, err, tt.err)
+		})
+	}
+
+	missing := "missing.json"
+	_, _, err := json.New(&missing).Provide(map[string]bool{}, zfg.ToString)
+	assert.Error(t, err)
+}
+
+func TestFS(t *testing.T) {
+	fsys := fstest.MapFS{
+		"config/default.json": {Data: []byte(`{"db": {"host": "localhost"}}`)},
+	}
+
+	path := "config/default.json"
+	found, _, err := json.New(&path, json.WithFS(fsys)).Provide(map[string]bool{"db.host": true}, zfg.ToString)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"db.host": "localhost"}, found)
+}
+
+func tempFile(t *testing.T, data string) string {
+	f, err := os.CreateTemp("", "tmpfile-")
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		require.NoError(t, f.Close())
+	})
+
+	_, err = f.WriteString(data)
+	require.NoError(t, err)
+
+	return f.Name()
+}
@@ -0,0 +1,42 @@
+package meta
+
+// Option is a read-only description of a registered option passed to providers, exposed as zerocfg.Option.
+// It is defined here so that providers imported by zerocfg itself (e.g. flag) can use it.
+type Option struct {
+	// Name is the option name, for an alias the name of the option it refers to.
+	Name string
+	// Type is the Value.Type() of the option, e.g. "int" or "strings".
+	Type        string
+	Description string
+
+	// IsAlias is true if the option is referred to by an alias.
+	IsAlias bool
+	// IsSlice is true for list options (e.g. Strs, Ints), their values are JSON arrays.
+	IsSlice bool
+	// IsBool is true for boolean options, an empty value sets them to true.
+	IsBool     bool
+	IsSecret   bool
+	IsRequired bool
+}
+
+// Awaited converts options to the awaited map of Provider.Provide:
+// true for option names, false for aliases.
+func Awaited(options map[string]Option) map[string]bool {
+	awaited := make(map[string]bool, len(options))
+	for k, o := range options {
+		awaited[k] = !o.IsAlias
+	}
+
+	return awaited
+}
+
+// FromAwaited converts the awaited map of Provider.Provide to options.
+// Only names are known: aliases refer to themselves and other fields are empty.
+func FromAwaited(awaited map[string]bool) map[string]Option {
+	options := make(map[string]Option, len(awaited))
+	for k, isOption := range awaited {
+		options[k] = Option{Name: k, IsAlias: !isOption}
+	}
+
+	return options
+}
@@ -0,0 +1,27 @@
+package meta_test
+
+import (
+	"testing"
+
+	"github.com/chaindead/zerocfg/meta"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAwaited(t *testing.T) {
+	options := map[string]meta.Option{
+		"db.port": {Name: "db.port", Type: "int"},
+		"p":       {Name: "db.port", Type: "int", IsAlias: true},
+	}
+
+	assert.Equal(t, map[string]bool{"db.port": true, "p": false}, meta.Awaited(options))
+}
+
+func TestFromAwaited(t *testing.T) {
+	options := meta.FromAwaited(map[string]bool{"db.port": true, "p": false})
+
+	assert.Equal(t, map[string]meta.Option{
+		"db.port": {Name: "db.port"},
+		"p":       {Name: "p", IsAlias: true},
+	}, options)
+	assert.Equal(t, map[string]bool{"db.port": true, "p": false}, meta.Awaited(options))
+}
@@ -1,5 +1,7 @@
 package zerocfg
 
+import "reflect"
+
 const noSource = "default"
 
 // node represents a single configuration option, including its name, description, aliases, value, and metadata.
@@ -12,6 +14,10 @@ type node struct {
 	isSecret    bool
 	isRequired  bool
 	caller      string
+
+	isResolvable bool
+	isResolved   bool   // value was set from a reference or masked by its provider
+	ref          string // reference waiting for resolution
 }
 
 func (n *node) pathName() string {
@@ -22,6 +28,36 @@ func (n *node) pathName() string {
 	return n.caller + ":" + n.Name
 }
 
+// option describes the node to providers.
+func (n *node) option() Option {
+	kind := reflect.Indirect(reflect.ValueOf(n.Value)).Kind()
+
+	return Option{
+		Name:        n.Name,
+		Type:        n.Value.Type(),
+		Description: n.Description,
+		IsSlice:     kind == reflect.Slice && !isBytes(n.Value),
+		IsBool:      kind == reflect.Bool,
+		IsSecret:    n.isSecret,
+		IsRequired:  n.isRequired,
+	}
+}
+
+// isBytes reports whether v is a byte slice, e.g. net.IP, those are not lists.
+func isBytes(v Value) bool {
+	return reflect.Indirect(reflect.ValueOf(v)).Type().Elem().Kind() == reflect.Uint8
+}
+
+// resolvable reports whether the option may be set by a reference.
+func (n *node) resolvable() bool {
+	return n.isSecret || n.isResolvable
+}
+
+// masked reports whether the value is hidden in rendered output.
+func (n *node) masked() bool {
+	return n.isSecret || n.isResolved
+}
+
 func (n *node) source() string {
 	if n.setSource == "" {
 		return noSource
@@ -65,6 +101,7 @@ func Alias(alias string) OptNode {
 
 // Secret returns an OptNode that marks a configuration option as secret.
 // Secret options are masked in rendered output (e.g., Show) to avoid leaking sensitive values.
+// They may be set by a reference (e.g. "file:///run/secrets/db"), see Resolvable.
 //
 // Example:
 //
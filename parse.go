@@ -1,8 +1,13 @@
 package zerocfg
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"strings"
+	"sync"
+
+	"github.com/chaindead/zerocfg/meta"
 )
 
 // Provider defines a configuration source for zerocfg.
@@ -23,6 +28,70 @@ type Provider interface {
 	Provide(awaited map[string]bool, conv func(any) string) (found, unknown map[string]string, err error)
 }
 
+// Option is a read-only description of a registered option, see OptionProvider.
+type Option = meta.Option
+
+// OptionProvider is a Provider receiving a description of every awaited name instead of the awaited map,
+// e.g. to parse values by option type. Options are keyed by option names and aliases,
+// Option.Name of an alias is the name of the option it refers to.
+//
+// Parse calls ProvideOptions instead of Provide for providers implementing OptionProvider.
+// Provide may be implemented with meta.FromAwaited, then only names of options are known:
+//
+//	func (p *MyProvider) Provide(awaited map[string]bool, conv func(any) string) (found, unknown map[string]string, err error) {
+//		return p.ProvideOptions(meta.FromAwaited(awaited), conv)
+//	}
+type OptionProvider interface {
+	Provider
+	ProvideOptions(options map[string]Option, conv func(any) string) (found, unknown map[string]string, err error)
+}
+
+// ContextProvider is an optional interface for providers doing I/O that can be cancelled (e.g. remote sources).
+//
+// ParseContext calls ProvideContext instead of ProvideOptions and Provide with its context,
+// Parse uses context.Background(). Providers should stop and return ctx.Err() once ctx is done.
+type ContextProvider interface {
+	Provider
+	ProvideContext(ctx context.Context, options map[string]Option, conv func(any) string) (found, unknown map[string]string, err error)
+}
+
+// legacyProvider adapts a Provider to OptionProvider, options are passed to Provide as the awaited map.
+type legacyProvider struct {
+	Provider
+}
+
+func (p legacyProvider) ProvideOptions(options map[string]Option, conv func(any) string) (found, unknown map[string]string, err error) {
+	return p.Provide(meta.Awaited(options), conv)
+}
+
+func asOptionProvider(p Provider) OptionProvider {
+	if op, ok := p.(OptionProvider); ok {
+		return op
+	}
+
+	return legacyProvider{p}
+}
+
+// Locator is an optional interface for providers that know where a key was defined
+// (e.g. a file name). Parse adds the location to set errors and to the message of unknown fields.
+type Locator interface {
+	Locate(key string) (location string, ok bool)
+}
+
+// Sourcer is an optional interface for providers combining several sources (e.g. files of a directory).
+// Source returns the name of the source a key came from, it is used instead of Type
+// as the source of the option (shown by Show) and to group unknown fields.
+type Sourcer interface {
+	Source(key string) (source string, ok bool)
+}
+
+// Masker is an optional interface for providers returning sensitive values of options
+// that are not secret (e.g. decrypted values). Values of masked keys are hidden by Show
+// like resolved references, and set errors report ErrResolvedValue instead of the error of the value.
+type Masker interface {
+	Masked(key string) bool
+}
+
 // Parse loads configuration from the provided sources in priority order.
 //
 // Usage:
@@ -36,37 +105,100 @@ type Provider interface {
 //
 // Behavior:
 //   - Applies each parser in order, setting values for registered options only.
+//   - Resolves references of secret and resolvable options (see Resolvable).
 //   - Returns an error if unknown options are found (unless ignored by IsUnknown).
 //
 // Error Handling:
 //   - UnknownFieldError: for unknown keys (see IsUnknown)
 //   - ErrRequired: for missing required options
+//   - ErrResolvedValue: for resolved values invalid for the option type
+//   - ErrNoResolver: for exec references without a registered resolver
 //   - ErrDoubleParse: if called multiple times
 func Parse(ps ...Provider) error {
+	return ParseContext(context.Background(), ps...)
+}
+
+// ParseContext is like Parse, but providers implementing ContextProvider receive ctx,
+// so a deadline or cancellation stops fetching from remote sources.
+//
+// Usage:
+//
+//	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+//	defer cancel()
+//
+//	err := zerocfg.ParseContext(ctx, env.New(), remote.New(url))
+//
+// Once ctx is done, no further providers are called. The error of the provider
+// wraps ctx.Err(), so errors.Is(err, context.DeadlineExceeded) reports an exceeded deadline.
+func ParseContext(ctx context.Context, ps ...Provider) error {
+	return c.parse(ctx, false, ps)
+}
+
+// ParseParallel is like ParseContext, but calls providers concurrently, so slow remote sources
+// are fetched at the same time. Providers must be safe to call concurrently with each other.
+//
+// Usage:
+//
+//	err := zerocfg.ParseParallel(ctx, env.New(), consul.New(addr, prefix), vault.New(addr))
+//
+// Values are applied in the order of providers once all of them return,
+// so priority and sources of options are the same as with ParseContext.
+// All provider errors are returned joined (see errors.Join), in the order of providers.
+func ParseParallel(ctx context.Context, ps ...Provider) error {
+	return c.parse(ctx, true, ps)
+}
+
+func (c *config) parse(ctx context.Context, parallel bool, ps []Provider) error {
 	if c.locked {
 		return ErrDoubleParse
 	}
 	c.locked = true
 	c.parsers = append(c.parsers, ps...)
-	awaited := c.awaited()
+	options := c.options()
 
-	uErr := make(UnknownFieldError)
-	for _, p := range c.parsers {
-		found, unknown, err := p.Provide(awaited, ToString)
+	fetch := func(_ int, p Provider) provided {
+		found, unknown, err := provide(ctx, p, options)
+		return provided{found: found, unknown: unknown, err: err}
+	}
+	if parallel {
+		results, err := provideAll(ctx, c.parsers, options)
 		if err != nil {
-			return fmt.Errorf("parse %q: %w", p.Type(), err)
+			return err
 		}
 
-		err = c.applyParser(p.Type(), found)
-		if err != nil {
-			return fmt.Errorf("apply %q: %w", p.Type(), err)
+		fetch = func(i int, _ Provider) provided {
+			return results[i]
+		}
+	}
+
+	unknown := unknownFields{keys: make(UnknownFieldError), locations: make(map[string]map[string]string)}
+	for i, p := range c.parsers {
+		r := fetch(i, p)
+		if r.err != nil {
+			return fmt.Errorf("parse %q: %w", p.Type(), r.err)
+		}
+
+		locate := locator(p)
+		mask := masker(p)
+
+		for source, vs := range bySource(p, r.found) {
+			err := c.applyParser(source, vs, locate, mask)
+			if err != nil {
+				return fmt.Errorf("apply %q: %w", p.Type(), err)
+			}
+		}
+
+		for source, vs := range bySource(p, r.unknown) {
+			unknown.add(source, vs, locate)
 		}
+	}
 
-		uErr.add(p.Type(), unknown)
+	if err := unknown.err(); err != nil {
+		return err
 	}
 
-	if len(uErr) != 0 {
-		return uErr
+	if err := c.resolve(); err != nil {
+		return err
 	}
 
 	var required []string
@@ -82,13 +214,119 @@ func Parse(ps ...Provider) error {
 	return nil
 }
 
-func (c *config) applyParser(source string, vs map[string]string) error {
+// provide calls the provider with ctx if it implements ContextProvider.
+// If ctx is done, the returned error wraps ctx.Err() even if the provider returns another error.
+func provide(ctx context.Context, p Provider, options map[string]Option) (found, unknown map[string]string, err error) {
+	if err = ctx.Err(); err != nil {
+		return nil, nil, err
+	}
+
+	if cp, ok := p.(ContextProvider); ok {
+		found, unknown, err = cp.ProvideContext(ctx, options, ToString)
+	} else {
+		found, unknown, err = asOptionProvider(p).ProvideOptions(options, ToString)
+	}
+
+	if err != nil && ctx.Err() != nil && !errors.Is(err, ctx.Err()) {
+		err = fmt.Errorf("%w: %w", ctx.Err(), err)
+	}
+
+	return found, unknown, err
+}
+
+// provided is the result of a provider.
+type provided struct {
+	found, unknown map[string]string
+	err            error
+}
+
+// provideAll calls providers concurrently and returns their results in the order of providers,
+// with errors of all failed providers joined.
+func provideAll(ctx context.Context, ps []Provider, options map[string]Option) ([]provided, error) {
+	results := make([]provided, len(ps))
+
+	var wg sync.WaitGroup
+	for i, p := range ps {
+		wg.Add(1)
+		go func(i int, p Provider) {
+			defer wg.Done()
+
+			found, unknown, err := provide(ctx, p, options)
+			results[i] = provided{found: found, unknown: unknown, err: err}
+		}(i, p)
+	}
+	wg.Wait()
+
+	var errs []error
+	for i, r := range results {
+		if r.err != nil {
+			errs = append(errs, fmt.Errorf("parse %q: %w", ps[i].Type(), r.err))
+		}
+	}
+
+	return results, errors.Join(errs...)
+}
+
+func (c *config) applyParser(source string, vs map[string]string, locate func(string) string, mask func(string) bool) error {
 	for k, v := range vs {
-		err := c.set(source, k, v)
+		err := c.set(source, k, v, mask(k))
 		if err != nil {
-			return fmt.Errorf("set key=%q: %w", k, err)
+			return fmt.Errorf("set key=%q%s: %w", k, locate(k), err)
 		}
 	}
 
 	return nil
 }
+
+// bySource groups values by the source they came from,
+// all values belong to p.Type() if the provider does not implement Sourcer.
+func bySource(p Provider, vs map[string]string) map[string]map[string]string {
+	s, ok := p.(Sourcer)
+	if !ok {
+		return map[string]map[string]string{p.Type(): vs}
+	}
+
+	groups := make(map[string]map[string]string)
+	for k, v := range vs {
+		source, found := s.Source(k)
+		if !found {
+			source = p.Type()
+		}
+
+		if groups[source] == nil {
+			groups[source] = make(map[string]string)
+		}
+		groups[source][k] = v
+	}
+
+	return groups
+}
+
+// locator returns a function formatting the location of a key as a suffix,
+// or an empty string if the provider does not implement Locator.
+func locator(p Provider) func(string) string {
+	l, ok := p.(Locator)
+
+	return func(key string) string {
+		if !ok {
+			return ""
+		}
+
+		loc, found := l.Locate(key)
+		if !found {
+			return ""
+		}
+
+		return " (" + loc + ")"
+	}
+}
+
+// masker returns a function reporting whether the value of a key is masked,
+// no value is masked if the provider does not implement Masker.
+func masker(p Provider) func(string) bool {
+	m, ok := p.(Masker)
+
+	return func(key string) bool {
+		return ok && m.Masked(key)
+	}
+}
@@ -0,0 +1,185 @@
+package properties
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"strconv"
+	"strings"
+
+	"github.com/chaindead/zerocfg/util"
+)
+
+var errSyntax = errors.New("syntax error")
+
+type Opt func(*Provider)
+
+// WithFS returns an Opt that reads the file from fsys instead of the operating system,
+// e.g. from an embed.FS with default configs. The path is slash-separated and relative to the root of fsys.
+func WithFS(fsys fs.FS) Opt {
+	return func(p *Provider) {
+		p.fsys = fsys
+	}
+}
+
+type Provider struct {
+	path *string
+	fsys fs.FS
+
+	lines map[string]int
+}
+
+// New creates a Provider reading a Java properties file, keys map directly to option names.
+//
+// The format follows java.util.Properties: lines starting with '#' or '!' are comments,
+// keys are separated from values by '=', ':' or whitespace, a trailing '\' continues
+// a line and escape sequences (including \uXXXX) are supported in keys and values.
+func New(path *string, opts ...Opt) *Provider {
+	p := &Provider{path: path}
+	for _, opt := range opts {
+		opt(p)
+	}
+
+	return p
+}
+
+func (p *Provider) Type() string {
+	return fmt.Sprintf("properties[%s]", util.ShortenPath(*p.path))
+}
+
+// Locate returns the position of a key as "file:line".
+func (p *Provider) Locate(key string) (string, bool) {
+	line, ok := p.lines[key]
+	if !ok {
+		return "", false
+	}
+
+	return fmt.Sprintf("%s:%d", *p.path, line), true
+}
+
+func (p *Provider) Provide(keys map[string]bool, conv func(any) string) (found, unknown map[string]string, err error) {
+	data, err := util.ReadFile(p.fsys, *p.path)
+	if err != nil {
+		return nil, nil, fmt.Errorf("read properties file: %w", err)
+	}
+
+	settings, lines, err := parse(string(data))
+	if err != nil {
+		return nil, nil, fmt.Errorf("unmarshal properties: %w", err)
+	}
+	p.lines = lines
+
+	found, unknown = util.Split(settings, keys, conv)
+
+	return found, unknown, nil
+}
+
+func parse(data string) (settings map[string]string, lines map[string]int, err error) {
+	settings, lines = make(map[string]string), make(map[string]int)
+
+	rows := strings.Split(strings.ReplaceAll(data, "\r\n", "\n"), "\n")
+	for i := 0; i < len(rows); i++ {
+		n := i + 1
+		row := strings.TrimLeft(rows[i], " \t\f")
+
+		if row == "" || row[0] == '#' || row[0] == '!' {
+			continue
+		}
+
+		// an odd number of trailing backslashes continues the row on the next one
+		for continues(row) && i+1 < len(rows) {
+			i++
+			row = row[:len(row)-1] + strings.TrimLeft(rows[i], " \t\f")
+		}
+
+		rawKey, rawValue := split(row)
+
+		k, err := unescape(rawKey)
+		if err != nil {
+			return nil, nil, fmt.Errorf("line %d: %w", n, err)
+		}
+
+		v, err := unescape(rawValue)
+		if err != nil {
+			return nil, nil, fmt.Errorf("line %d: %w", n, err)
+		}
+
+		settings[k] = v
+		lines[k] = n
+	}
+
+	return settings, lines, nil
+}
+
+func continues(row string) bool {
+	var count int
+	for i := len(row) - 1; i >= 0 && row[i] == '\\'; i-- {
+		count++
+	}
+
+	return count%2 == 1
+}
+
+// split separates the key at the first unescaped '=', ':' or whitespace.
+func split(row string) (key, value string) {
+	for i := 0; i < len(row); i++ {
+		switch row[i] {
+		case '\\':
+			i++
+		case '=', ':':
+			return row[:i], strings.TrimLeft(row[i+1:], " \t\f")
+		case ' ', '\t', '\f':
+			rest := strings.TrimLeft(row[i:], " \t\f")
+			if rest != "" && (rest[0] == '=' || rest[0] == ':') {
+				rest = rest[1:]
+			}
+
+			return row[:i], strings.TrimLeft(rest, " \t\f")
+		}
+	}
+
+	return row, ""
+}
+
+func unescape(s string) (string, error) {
+	if !strings.Contains(s, `\`) {
+		return s, nil
+	}
+
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] != '\\' || i+1 == len(s) {
+			b.WriteByte(s[i])
+			continue
+		}
+
+		i++
+		switch c := s[i]; c {
+		case 't':
+			b.WriteByte('\t')
+		case 'n':
+			b.WriteByte('\n')
+		case 'r':
+			b.WriteByte('\r')
+		case 'f':
+			b.WriteByte('\f')
+		case 'u':
+			if i+5 > len(s) {
+				return "", fmt.Errorf("%w: invalid unicode escape %q", errSyntax, s[i-1:])
+			}
+
+			code, err := strconv.ParseUint(s[i+1:i+5], 16, 16)
+			if err != nil {
+				return "", fmt.Errorf("%w: invalid unicode escape %q", errSyntax, s[i-1:i+5])
+			}
+
+			b.WriteRune(rune(code))
+			i += 4
+		default:
+			// other escaped characters (e.g. '=', ':', '#', '\') stand for themselves
+			b.WriteByte(c)
+		}
+	}
+
+	return b.String(), nil
+}
@@ -0,0 +1,135 @@
+package properties_test
+
+import (
+	"os"
+	"testing"
+	"testing/fstest"
+
+	zfg "github.com/chaindead/zerocfg"
+	"github.com/chaindead/zerocfg/properties"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		awaited map[string]bool
+		found   map[string]string
+		unknown map[string]string
+	}{
+		{
+			name: "separators",
+			input: `
+db.host=localhost
+db.port : 5432
+db.user   admin
+db.empty
+app.name = app`,
+			awaited: map[string]bool{"db.host": true, "db.port": true, "db.user": true, "db.empty": true},
+			found: map[string]string{
+				"db.host":  "localhost",
+				"db.port":  "5432",
+				"db.user":  "admin",
+				"db.empty": "",
+			},
+			unknown: map[string]string{"app.name": "app"},
+		},
+		{
+			name: "comments",
+			input: `
+# comment
+! comment
+  # indented comment
+key = value # not a comment`,
+			awaited: map[string]bool{"key": true},
+			found:   map[string]string{"key": "value # not a comment"},
+		},
+		{
+			name: "continuation",
+			input: `
+hosts = a,\
+        b,\
+        c
+path = C:\\dir\\`,
+			awaited: map[string]bool{"hosts": true, "path": true},
+			found:   map[string]string{"hosts": "a,b,c", "path": `C:\dir\`},
+		},
+		{
+			name: "escapes",
+			input: `
+key\=with\:sep = tab\there\nnext \u00e9
+spaced\ key = v`,
+			awaited: map[string]bool{"key=with:sep": true, "spaced key": true},
+			found: map[string]string{
+				"key=with:sep": "tab\there\nnext é",
+				"spaced key":   "v",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.unknown == nil {
+				tt.unknown = map[string]string{}
+			}
+
+			name := tempFile(t, tt.input)
+			p := properties.New(&name)
+
+			found, unknown, err := p.Provide(tt.awaited, zfg.ToString)
+			require.NoError(t, err)
+
+			assert.Equal(t, tt.found, found)
+			assert.Equal(t, tt.unknown, unknown)
+		})
+	}
+}
+
+func TestParse_Error(t *testing.T) {
+	name := tempFile(t, "a = 1\nb = \\u00zz")
+
+	_, _, err := properties.New(&name).Provide(map[string]bool{}, zfg.ToString)
+	require.ErrorContains(t, err, `line 2: syntax error: invalid unicode escape "\\u00zz"`)
+
+	missing := "missing.properties"
+	_, _, err = properties.New(&missing).Provide(map[string]bool{}, zfg.ToString)
+	assert.Error(t, err)
+}
+
+func TestLocate(t *testing.T) {
+	name := tempFile(t, "a = 1\n\ndb.port = 5432")
+	p := properties.New(&name)
+
+	_, _, err := p.Provide(map[string]bool{}, zfg.ToString)
+	require.NoError(t, err)
+
+	loc, ok := p.Locate("db.port")
+	assert.True(t, ok)
+	assert.Equal(t, name+":3", loc)
+}
+
+func TestFS(t *testing.T) {
+	fsys := fstest.MapFS{
+		"config/default.properties": {Data: []byte("db.host = localhost")},
+	}
+
+	path := "config/default.properties"
+	found, _, err := properties.New(&path, properties.WithFS(fsys)).Provide(map[string]bool{"db.host": true}, zfg.ToString)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"db.host": "localhost"}, found)
+}
+
+func tempFile(t *testing.T, data string) string {
+	f, err := os.CreateTemp("", "tmpfile-")
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		require.NoError(t, f.Close())
+	})
+
+	_, err = f.WriteString(data)
+	require.NoError(t, err)
+
+	return f.Name()
+}
@@ -0,0 +1,84 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+
+	zfg "github.com/chaindead/zerocfg"
+	"github.com/chaindead/zerocfg/meta"
+)
+
+// FallbackProvider uses a secondary provider if the primary one fails, see Fallback.
+type FallbackProvider struct {
+	primary, secondary zfg.Provider
+
+	used zfg.Provider
+}
+
+// Fallback wraps two providers to use secondary only if primary fails:
+//
+//	zfg.Parse(
+//		provider.Fallback(remote.New(url), yaml.New(cachePath)),
+//	)
+//
+// Type is the type of primary, values of secondary are sourced from secondary.Type().
+// If both fail, the returned error wraps both errors.
+func Fallback(primary, secondary zfg.Provider) *FallbackProvider {
+	return &FallbackProvider{
+		primary:   primary,
+		secondary: secondary,
+		used:      primary,
+	}
+}
+
+func (f *FallbackProvider) Type() string {
+	return f.primary.Type()
+}
+
+func (f *FallbackProvider) Provide(awaited map[string]bool, conv func(any) string) (found, unknown map[string]string, err error) {
+	return f.ProvideContext(context.Background(), meta.FromAwaited(awaited), conv)
+}
+
+func (f *FallbackProvider) ProvideOptions(options map[string]zfg.Option, conv func(any) string) (found, unknown map[string]string, err error) {
+	return f.ProvideContext(context.Background(), options, conv)
+}
+
+func (f *FallbackProvider) ProvideContext(ctx context.Context, options map[string]zfg.Option, conv func(any) string) (found, unknown map[string]string, err error) {
+	f.used = f.primary
+	found, unknown, err = provide(ctx, f.primary, options, conv)
+	if err == nil {
+		return found, unknown, nil
+	}
+
+	f.used = f.secondary
+	found, unknown, sErr := provide(ctx, f.secondary, options, conv)
+	if sErr != nil {
+		return nil, nil, fmt.Errorf("%w, fallback %q: %w", err, f.secondary.Type(), sErr)
+	}
+
+	return found, unknown, nil
+}
+
+// Locate returns the location of a key if the used provider implements zfg.Locator.
+func (f *FallbackProvider) Locate(key string) (string, bool) {
+	return locate(f.used, key)
+}
+
+// Masked reports whether the value of a key is masked if the used provider implements zfg.Masker.
+func (f *FallbackProvider) Masked(key string) bool {
+	return masked(f.used, key)
+}
+
+// Source returns the source of a key in the used provider,
+// the type of secondary if it is used and does not implement zfg.Sourcer.
+func (f *FallbackProvider) Source(key string) (string, bool) {
+	if s, ok := source(f.used, key); ok {
+		return s, true
+	}
+
+	if f.used == f.secondary {
+		return f.secondary.Type(), true
+	}
+
+	return "", false
+}
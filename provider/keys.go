@@ -0,0 +1,126 @@
+package provider
+
+import (
+	"context"
+	"strings"
+
+	zfg "github.com/chaindead/zerocfg"
+	"github.com/chaindead/zerocfg/meta"
+)
+
+// KeyProvider maps keys of the wrapped provider to option names, see WithPrefix and Rename.
+type KeyProvider struct {
+	wrapper
+
+	// toProvider returns the key of an option name in the wrapped provider.
+	toProvider func(name string) string
+	// fromProvider returns the option name of a key, false if the key is out of scope.
+	fromProvider func(key string) (string, bool)
+}
+
+// WithPrefix wraps a provider whose keys are option names under prefix:
+//
+//	zfg.Parse(
+//		provider.WithPrefix(yaml.New(path), "app"),
+//	)
+//
+// Key "app.db.host" of the provider sets option "db.host". Keys outside of prefix are ignored,
+// so they are never unknown.
+func WithPrefix(p zfg.Provider, prefix string) *KeyProvider {
+	prefix = strings.Trim(prefix, ".")
+	if prefix == "" {
+		return Rename(p, nil)
+	}
+
+	return &KeyProvider{
+		wrapper: wrapper{p: p},
+		toProvider: func(name string) string {
+			return prefix + "." + name
+		},
+		fromProvider: func(key string) (string, bool) {
+			return strings.CutPrefix(key, prefix+".")
+		},
+	}
+}
+
+// Rename wraps a provider to rename its keys, mapping is provider keys to option names:
+//
+//	zfg.Parse(
+//		provider.Rename(yaml.New(path), map[string]string{"database.url": "db.dsn"}),
+//	)
+//
+// Keys not in mapping are option names as is.
+func Rename(p zfg.Provider, mapping map[string]string) *KeyProvider {
+	names := make(map[string]string, len(mapping))
+	for key, name := range mapping {
+		names[name] = key
+	}
+
+	return &KeyProvider{
+		wrapper: wrapper{p: p},
+		toProvider: func(name string) string {
+			if key, ok := names[name]; ok {
+				return key
+			}
+
+			return name
+		},
+		fromProvider: func(key string) (string, bool) {
+			if name, ok := mapping[key]; ok {
+				return name, true
+			}
+
+			return key, true
+		},
+	}
+}
+
+func (k *KeyProvider) Provide(awaited map[string]bool, conv func(any) string) (found, unknown map[string]string, err error) {
+	return k.ProvideContext(context.Background(), meta.FromAwaited(awaited), conv)
+}
+
+func (k *KeyProvider) ProvideOptions(options map[string]zfg.Option, conv func(any) string) (found, unknown map[string]string, err error) {
+	return k.ProvideContext(context.Background(), options, conv)
+}
+
+func (k *KeyProvider) ProvideContext(ctx context.Context, options map[string]zfg.Option, conv func(any) string) (found, unknown map[string]string, err error) {
+	mapped := make(map[string]zfg.Option, len(options))
+	for name, o := range options {
+		o.Name = k.toProvider(o.Name)
+		mapped[k.toProvider(name)] = o
+	}
+
+	found, unknown, err = provide(ctx, k.p, mapped, conv)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return k.names(found), k.names(unknown), nil
+}
+
+// names maps keys of vs to option names, dropping keys out of scope.
+func (k *KeyProvider) names(vs map[string]string) map[string]string {
+	out := make(map[string]string, len(vs))
+	for key, v := range vs {
+		if name, ok := k.fromProvider(key); ok {
+			out[name] = v
+		}
+	}
+
+	return out
+}
+
+// Locate returns the location of an option if the wrapped provider implements zfg.Locator.
+func (k *KeyProvider) Locate(name string) (string, bool) {
+	return locate(k.p, k.toProvider(name))
+}
+
+// Source returns the source of an option if the wrapped provider implements zfg.Sourcer.
+func (k *KeyProvider) Source(name string) (string, bool) {
+	return source(k.p, k.toProvider(name))
+}
+
+// Masked reports whether the value of an option is masked if the wrapped provider implements zfg.Masker.
+func (k *KeyProvider) Masked(name string) bool {
+	return masked(k.p, k.toProvider(name))
+}
@@ -0,0 +1,64 @@
+package provider
+
+import (
+	"context"
+	"log"
+
+	zfg "github.com/chaindead/zerocfg"
+	"github.com/chaindead/zerocfg/meta"
+)
+
+type OptionalOpt func(*OptionalProvider)
+
+// WithWarn returns an OptionalOpt that sets the function receiving ignored errors,
+// by default they are written with the standard logger.
+func WithWarn(warn func(err error)) OptionalOpt {
+	return func(o *OptionalProvider) {
+		o.warn = warn
+	}
+}
+
+// OptionalProvider ignores errors of the wrapped provider, see Optional.
+type OptionalProvider struct {
+	wrapper
+	warn func(err error)
+}
+
+// Optional wraps a provider whose errors do not fail parsing:
+//
+//	zfg.Parse(
+//		provider.Optional(yaml.New(localPath)),
+//	)
+//
+// If the provider fails, the error is reported as a warning and no values are provided,
+// so options keep values of the next providers or defaults.
+func Optional(p zfg.Provider, opts ...OptionalOpt) *OptionalProvider {
+	o := &OptionalProvider{wrapper: wrapper{p: p}}
+	o.warn = func(err error) {
+		log.Printf("zerocfg: ignore %q: %v", o.Type(), err)
+	}
+
+	for _, opt := range opts {
+		opt(o)
+	}
+
+	return o
+}
+
+func (o *OptionalProvider) Provide(awaited map[string]bool, conv func(any) string) (found, unknown map[string]string, err error) {
+	return o.ProvideContext(context.Background(), meta.FromAwaited(awaited), conv)
+}
+
+func (o *OptionalProvider) ProvideOptions(options map[string]zfg.Option, conv func(any) string) (found, unknown map[string]string, err error) {
+	return o.ProvideContext(context.Background(), options, conv)
+}
+
+func (o *OptionalProvider) ProvideContext(ctx context.Context, options map[string]zfg.Option, conv func(any) string) (found, unknown map[string]string, err error) {
+	found, unknown, err = provide(ctx, o.p, options, conv)
+	if err != nil {
+		o.warn(err)
+		return make(map[string]string), make(map[string]string), nil
+	}
+
+	return found, unknown, nil
+}
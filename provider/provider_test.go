@@ -0,0 +1,255 @@
+package provider_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	zfg "github.com/chaindead/zerocfg"
+	"github.com/chaindead/zerocfg/meta"
+	"github.com/chaindead/zerocfg/provider"
+	"github.com/chaindead/zerocfg/static"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var errUnavailable = errors.New("unavailable")
+
+// flaky fails the first fails calls, then provides its values.
+type flaky struct {
+	*static.Provider
+	name  string
+	fails int
+	calls int
+}
+
+func newFlaky(name string, fails int, values map[string]any) *flaky {
+	return &flaky{Provider: static.New(values), name: name, fails: fails}
+}
+
+func (f *flaky) Type() string {
+	return f.name
+}
+
+func (f *flaky) Provide(awaited map[string]bool, conv func(any) string) (found, unknown map[string]string, err error) {
+	f.calls++
+	if f.calls <= f.fails {
+		return nil, nil, errUnavailable
+	}
+
+	return f.Provider.Provide(awaited, conv)
+}
+
+// located is a zfg.Locator, zfg.Sourcer and zfg.Masker.
+type located struct {
+	*flaky
+}
+
+func (l located) Locate(key string) (string, bool) {
+	return "file.yaml", true
+}
+
+func (l located) Source(key string) (string, bool) {
+	return "file.yaml", true
+}
+
+func (l located) Masked(key string) bool {
+	return true
+}
+
+var (
+	awaited = map[string]bool{"db.host": true}
+	options = map[string]zfg.Option{"db.host": {Name: "db.host", Type: "string"}}
+)
+
+func TestRetry(t *testing.T) {
+	tests := []struct {
+		name     string
+		fails    int
+		attempts int
+		err      string
+		calls    int
+	}{
+		{name: "first attempt", fails: 0, attempts: 3, calls: 1},
+		{name: "last attempt", fails: 2, attempts: 3, calls: 3},
+		{name: "all attempts", fails: 3, attempts: 3, calls: 3, err: "after 3 attempts: unavailable"},
+		{name: "single attempt", fails: 1, attempts: 0, calls: 1, err: "unavailable"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := newFlaky("flaky", tt.fails, map[string]any{"db.host": "localhost"})
+
+			found, _, err := provider.Retry(f, tt.attempts, time.Millisecond).Provide(awaited, zfg.ToString)
+			assert.Equal(t, tt.calls, f.calls)
+			if tt.err != "" {
+				require.EqualError(t, err, tt.err)
+				require.ErrorIs(t, err, errUnavailable)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, map[string]string{"db.host": "localhost"}, found)
+		})
+	}
+}
+
+func TestRetry_Context(t *testing.T) {
+	f := newFlaky("flaky", 3, nil)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(20*time.Millisecond, cancel)
+
+	_, _, err := provider.Retry(f, 3, time.Hour).ProvideContext(ctx, options, zfg.ToString)
+	require.ErrorIs(t, err, errUnavailable)
+	assert.Equal(t, 1, f.calls)
+}
+
+func TestFallback(t *testing.T) {
+	values := map[string]any{"db.host": "primary"}
+	fallback := map[string]any{"db.host": "secondary"}
+
+	// primary succeeds
+	p := provider.Fallback(located{newFlaky("remote", 0, values)}, newFlaky("cache", 0, fallback))
+	found, _, err := p.Provide(awaited, zfg.ToString)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"db.host": "primary"}, found)
+	assert.Equal(t, "remote", p.Type())
+
+	loc, ok := p.Locate("db.host")
+	assert.True(t, ok)
+	assert.Equal(t, "file.yaml", loc)
+	assert.True(t, p.Masked("db.host"))
+
+	// primary fails, values are sourced from secondary
+	p = provider.Fallback(located{newFlaky("remote", 1, values)}, newFlaky("cache", 0, fallback))
+	found, _, err = p.ProvideOptions(options, zfg.ToString)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"db.host": "secondary"}, found)
+
+	src, ok := p.Source("db.host")
+	assert.True(t, ok)
+	assert.Equal(t, "cache", src)
+
+	_, ok = p.Locate("db.host")
+	assert.False(t, ok)
+	assert.False(t, p.Masked("db.host"))
+
+	// both fail
+	errCache := errors.New("no cache")
+	_, _, err = provider.Fallback(newFlaky("remote", 1, nil), failing{errCache}).Provide(awaited, zfg.ToString)
+	require.ErrorIs(t, err, errUnavailable)
+	require.ErrorIs(t, err, errCache)
+	require.EqualError(t, err, `unavailable, fallback "failing": no cache`)
+}
+
+type failing struct {
+	err error
+}
+
+func (f failing) Type() string {
+	return "failing"
+}
+
+func (f failing) Provide(map[string]bool, func(any) string) (found, unknown map[string]string, err error) {
+	return nil, nil, f.err
+}
+
+func TestOptional(t *testing.T) {
+	var warnings []error
+	warn := provider.WithWarn(func(err error) {
+		warnings = append(warnings, err)
+	})
+
+	found, unknown, err := provider.Optional(newFlaky("local", 1, nil), warn).Provide(awaited, zfg.ToString)
+	require.NoError(t, err)
+	assert.Empty(t, found)
+	assert.Empty(t, unknown)
+	assert.Equal(t, []error{errUnavailable}, warnings)
+
+	p := provider.Optional(located{newFlaky("local", 0, map[string]any{"db.host": "localhost"})}, warn)
+	found, _, err = p.ProvideOptions(options, zfg.ToString)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"db.host": "localhost"}, found)
+	assert.Len(t, warnings, 1)
+	assert.Equal(t, "local", p.Type())
+
+	src, ok := p.Source("db.host")
+	assert.True(t, ok)
+	assert.Equal(t, "file.yaml", src)
+}
+
+func TestCompose(t *testing.T) {
+	remote := newFlaky("remote", 5, nil)
+	cache := newFlaky("cache", 0, map[string]any{"db.host": "cached"})
+
+	p := provider.Fallback(provider.Retry(remote, 2, time.Millisecond), provider.Optional(cache))
+	found, _, err := p.ProvideContext(context.Background(), options, zfg.ToString)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"db.host": "cached"}, found)
+	assert.Equal(t, 2, remote.calls)
+}
+
+func TestWithPrefix(t *testing.T) {
+	values := map[string]any{
+		"app":   map[string]any{"db": map[string]any{"host": "localhost"}, "stray": 1},
+		"other": map[string]any{"db": map[string]any{"host": "ignored"}},
+	}
+
+	for _, prefix := range []string{"app", "app."} {
+		p := provider.WithPrefix(located{newFlaky("yaml", 0, values)}, prefix)
+
+		found, unknown, err := p.Provide(awaited, zfg.ToString)
+		require.NoError(t, err)
+		assert.Equal(t, map[string]string{"db.host": "localhost"}, found)
+		assert.Equal(t, map[string]string{"stray": "1"}, unknown)
+		assert.Equal(t, "yaml", p.Type())
+
+		loc, ok := p.Locate("db.host")
+		assert.True(t, ok)
+		assert.Equal(t, "file.yaml", loc)
+		assert.True(t, p.Masked("db.host"))
+	}
+
+	// options are passed with prefixed names
+	r := &recorder{Provider: static.New(values)}
+	found, _, err := provider.WithPrefix(r, "app").ProvideOptions(map[string]zfg.Option{
+		"db.host": {Name: "db.host"},
+		"h":       {Name: "db.host", IsAlias: true},
+	}, zfg.ToString)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"db.host": "localhost"}, found)
+	assert.Equal(t, map[string]zfg.Option{
+		"app.db.host": {Name: "app.db.host"},
+		"app.h":       {Name: "app.db.host", IsAlias: true},
+	}, r.options)
+}
+
+func TestRename(t *testing.T) {
+	values := map[string]any{
+		"database": map[string]any{"url": "postgres://localhost"},
+		"port":     5432,
+		"stray":    1,
+	}
+	awaited := map[string]bool{"db.dsn": true, "port": true}
+
+	p := provider.Rename(static.New(values), map[string]string{"database.url": "db.dsn", "stray": "renamed"})
+
+	found, unknown, err := p.Provide(awaited, zfg.ToString)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"db.dsn": "postgres://localhost", "port": "5432"}, found)
+	assert.Equal(t, map[string]string{"renamed": "1"}, unknown)
+}
+
+// recorder is a zfg.OptionProvider recording options it receives.
+type recorder struct {
+	*static.Provider
+	options map[string]zfg.Option
+}
+
+func (r *recorder) ProvideOptions(options map[string]zfg.Option, conv func(any) string) (found, unknown map[string]string, err error) {
+	r.options = options
+
+	return r.Provide(meta.Awaited(options), conv)
+}
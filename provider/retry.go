@@ -0,0 +1,82 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	zfg "github.com/chaindead/zerocfg"
+	"github.com/chaindead/zerocfg/meta"
+)
+
+// RetryProvider calls the wrapped provider again if it fails, see Retry.
+type RetryProvider struct {
+	wrapper
+	attempts int
+	backoff  time.Duration
+}
+
+// Retry wraps a provider to call it up to attempts times until it succeeds:
+//
+//	zfg.Parse(
+//		provider.Retry(remote.New(url), 3, time.Second),
+//	)
+//
+// The first retry waits backoff, every next one twice as long as the previous.
+// All errors are retried, the error of the last attempt is returned.
+// Waiting stops once the context of zfg.ParseContext is done.
+func Retry(p zfg.Provider, attempts int, backoff time.Duration) *RetryProvider {
+	if attempts < 1 {
+		attempts = 1
+	}
+
+	return &RetryProvider{
+		wrapper:  wrapper{p: p},
+		attempts: attempts,
+		backoff:  backoff,
+	}
+}
+
+func (r *RetryProvider) Provide(awaited map[string]bool, conv func(any) string) (found, unknown map[string]string, err error) {
+	return r.ProvideContext(context.Background(), meta.FromAwaited(awaited), conv)
+}
+
+func (r *RetryProvider) ProvideOptions(options map[string]zfg.Option, conv func(any) string) (found, unknown map[string]string, err error) {
+	return r.ProvideContext(context.Background(), options, conv)
+}
+
+func (r *RetryProvider) ProvideContext(ctx context.Context, options map[string]zfg.Option, conv func(any) string) (found, unknown map[string]string, err error) {
+	backoff := r.backoff
+	attempt := 1
+	for {
+		found, unknown, err = provide(ctx, r.p, options, conv)
+		if err == nil {
+			return found, unknown, nil
+		}
+
+		if attempt == r.attempts || !sleep(ctx, backoff) {
+			break
+		}
+		attempt++
+		backoff *= 2
+	}
+
+	if attempt == 1 {
+		return nil, nil, err
+	}
+
+	return nil, nil, fmt.Errorf("after %d attempts: %w", attempt, err)
+}
+
+// sleep waits for d, it returns false if ctx is done first.
+func sleep(ctx context.Context, d time.Duration) bool {
+	t := time.NewTimer(d)
+	defer t.Stop()
+
+	select {
+	case <-ctx.Done():
+		return false
+	case <-t.C:
+		return true
+	}
+}
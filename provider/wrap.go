@@ -0,0 +1,66 @@
+package provider
+
+import (
+	"context"
+
+	zfg "github.com/chaindead/zerocfg"
+	"github.com/chaindead/zerocfg/meta"
+)
+
+// wrapper forwards optional interfaces of zfg providers to the wrapped provider.
+type wrapper struct {
+	p zfg.Provider
+}
+
+func (w wrapper) Type() string {
+	return w.p.Type()
+}
+
+// Locate returns the location of a key if the wrapped provider implements zfg.Locator.
+func (w wrapper) Locate(key string) (string, bool) {
+	return locate(w.p, key)
+}
+
+// Source returns the source of a key if the wrapped provider implements zfg.Sourcer.
+func (w wrapper) Source(key string) (string, bool) {
+	return source(w.p, key)
+}
+
+// Masked reports whether the value of a key is masked if the wrapped provider implements zfg.Masker.
+func (w wrapper) Masked(key string) bool {
+	return masked(w.p, key)
+}
+
+// provide calls p with ctx and options if it implements zfg.ContextProvider or zfg.OptionProvider.
+func provide(ctx context.Context, p zfg.Provider, options map[string]zfg.Option, conv func(any) string) (found, unknown map[string]string, err error) {
+	switch p := p.(type) {
+	case zfg.ContextProvider:
+		return p.ProvideContext(ctx, options, conv)
+	case zfg.OptionProvider:
+		return p.ProvideOptions(options, conv)
+	default:
+		return p.Provide(meta.Awaited(options), conv)
+	}
+}
+
+func locate(p zfg.Provider, key string) (string, bool) {
+	if l, ok := p.(zfg.Locator); ok {
+		return l.Locate(key)
+	}
+
+	return "", false
+}
+
+func source(p zfg.Provider, key string) (string, bool) {
+	if s, ok := p.(zfg.Sourcer); ok {
+		return s.Source(key)
+	}
+
+	return "", false
+}
+
+func masked(p zfg.Provider, key string) bool {
+	m, ok := p.(zfg.Masker)
+
+	return ok && m.Masked(key)
+}
@@ -0,0 +1,392 @@
+package remote
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"strings"
+	"sync"
+	"time"
+
+	zjson "github.com/chaindead/zerocfg/json"
+	"github.com/chaindead/zerocfg/meta"
+	"github.com/chaindead/zerocfg/toml"
+	"github.com/chaindead/zerocfg/util"
+	"github.com/chaindead/zerocfg/yaml"
+)
+
+const defaultTimeout = 10 * time.Second
+
+// Supported document formats.
+const (
+	FormatYAML = "yaml"
+	FormatJSON = "json"
+	FormatTOML = "toml"
+)
+
+var (
+	errUnknownFormat = errors.New("unknown document format")
+	errStatus        = errors.New("unexpected status")
+)
+
+var decoders = map[string]func([]byte) (map[string]any, error){
+	FormatYAML: yaml.Decode,
+	FormatJSON: zjson.Decode,
+	FormatTOML: toml.Decode,
+}
+
+var mediaTypes = map[string]string{
+	"application/yaml":   FormatYAML,
+	"application/x-yaml": FormatYAML,
+	"text/yaml":          FormatYAML,
+	"text/x-yaml":        FormatYAML,
+	"application/json":   FormatJSON,
+	"text/json":          FormatJSON,
+	"application/toml":   FormatTOML,
+	"text/toml":          FormatTOML,
+}
+
+var extensions = map[string]string{
+	".yaml": FormatYAML,
+	".yml":  FormatYAML,
+	".json": FormatJSON,
+	".toml": FormatTOML,
+}
+
+type Opt func(*Provider)
+
+// WithTimeout returns an Opt that limits fetching the document, including reading the body, 10 seconds by default.
+func WithTimeout(d time.Duration) Opt {
+	return func(p *Provider) {
+		p.timeout = d
+	}
+}
+
+// WithBearer returns an Opt that authorizes requests with a bearer token.
+func WithBearer(token string) Opt {
+	return WithHeader("Authorization", "Bearer "+token)
+}
+
+// WithBasicAuth returns an Opt that authorizes requests with a user and password.
+func WithBasicAuth(user, password string) Opt {
+	return func(p *Provider) {
+		p.user, p.password = user, password
+	}
+}
+
+// WithHeader returns an Opt that adds a header to requests.
+func WithHeader(key, value string) Opt {
+	return func(p *Provider) {
+		p.header.Set(key, value)
+	}
+}
+
+// WithFormat returns an Opt that sets the document format instead of detecting it
+// by Content-Type or URL extension.
+func WithFormat(format string) Opt {
+	return func(p *Provider) {
+		p.format = format
+	}
+}
+
+// WithCache returns an Opt that stores the last fetched document in a local file.
+// The cached ETag is used for conditional requests and the file is used as a fallback
+// when the service is unreachable.
+func WithCache(path string) Opt {
+	return func(p *Provider) {
+		p.cache = path
+	}
+}
+
+// WithClient returns an Opt that sets the http client fetching the document, e.g. with a proxy or custom TLS settings.
+func WithClient(client *http.Client) Opt {
+	return func(p *Provider) {
+		p.client = client
+	}
+}
+
+// Provider fetches a configuration document (yaml, json or toml) over http(s).
+type Provider struct {
+	url      *string
+	timeout  time.Duration
+	header   http.Header
+	user     string
+	password string
+	format   string
+	cache    string
+	client   *http.Client
+
+	mu   sync.Mutex
+	last document
+}
+
+type document struct {
+	Body   []byte `json:"-"`
+	Format string `json:"format"`
+	ETag   string `json:"etag"`
+}
+
+// New creates a Provider fetching a document from url.
+//
+// The format is detected by Content-Type of the response or by extension of the url path.
+// Nested keys are mapped to dotted option names, as in the file providers.
+func New(url *string, opts ...Opt) *Provider {
+	p := &Provider{
+		url:     url,
+		timeout: defaultTimeout,
+		header:  make(http.Header),
+		client:  http.DefaultClient,
+	}
+
+	for _, opt := range opts {
+		opt(p)
+	}
+
+	return p
+}
+
+func (p *Provider) Type() string {
+	return fmt.Sprintf("remote[%s]", redact(*p.url))
+}
+
+func (p *Provider) Provide(keys map[string]bool, conv func(any) string) (found, unknown map[string]string, err error) {
+	return p.provide(context.Background(), keys, conv)
+}
+
+// ProvideContext fetches the document with ctx. If ctx is cancelled the cache is not used,
+// an exceeded deadline falls back to it as an unreachable service.
+func (p *Provider) ProvideContext(ctx context.Context, options map[string]meta.Option, conv func(any) string) (found, unknown map[string]string, err error) {
+	return p.provide(ctx, meta.Awaited(options), conv)
+}
+
+func (p *Provider) provide(ctx context.Context, keys map[string]bool, conv func(any) string) (found, unknown map[string]string, err error) {
+	doc, err := p.fetch(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	settings, err := decoders[doc.Format](doc.Body)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	found, unknown = util.Flatten(settings, keys, conv)
+
+	return found, unknown, nil
+}
+
+// Watch polls the url every interval and calls onChange when the document changes,
+// until ctx is done. Failed polls are skipped, the document is checked again on the next tick.
+func (p *Provider) Watch(ctx context.Context, interval time.Duration, onChange func()) error {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+
+		p.mu.Lock()
+		last := p.last
+		p.mu.Unlock()
+
+		doc, modified, err := p.request(ctx, last.ETag)
+		if err != nil || !modified || bytes.Equal(doc.Body, last.Body) {
+			continue
+		}
+
+		p.remember(doc)
+		onChange()
+	}
+}
+
+// fetch requests the document, falling back to the cache if the service is unreachable.
+func (p *Provider) fetch(ctx context.Context) (document, error) {
+	cached, cacheErr := p.readCache()
+
+	var etag string
+	if cacheErr == nil {
+		etag = cached.ETag
+	}
+
+	doc, modified, err := p.request(ctx, etag)
+	switch {
+	case err != nil && cacheErr == nil && fallback(err):
+		doc = cached
+	case err != nil:
+		return document{}, err
+	case !modified:
+		doc = cached
+	}
+
+	p.remember(doc)
+
+	return doc, nil
+}
+
+func (p *Provider) remember(doc document) {
+	p.mu.Lock()
+	p.last = doc
+	p.mu.Unlock()
+
+	// cache is a best effort fallback, errors are not fatal
+	_ = p.writeCache(doc)
+}
+
+// request fetches the document, modified is false if the server responds 304 Not Modified to etag.
+func (p *Provider) request(ctx context.Context, etag string) (doc document, modified bool, err error) {
+	ctx, cancel := context.WithTimeout(ctx, p.timeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, *p.url, nil)
+	if err != nil {
+		return document{}, false, fmt.Errorf("create request: %w", err)
+	}
+
+	req.Header = p.header.Clone()
+	if p.user != "" || p.password != "" {
+		req.SetBasicAuth(p.user, p.password)
+	}
+	if etag != "" {
+		req.Header.Set("If-None-Match", etag)
+	}
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return document{}, false, fmt.Errorf("fetch: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotModified && etag != "" {
+		return document{}, false, nil
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return document{}, false, &statusError{code: resp.StatusCode}
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return document{}, false, fmt.Errorf("read body: %w", err)
+	}
+
+	format, err := p.detect(resp)
+	if err != nil {
+		return document{}, false, err
+	}
+
+	return document{Body: body, Format: format, ETag: resp.Header.Get("ETag")}, true, nil
+}
+
+func (p *Provider) detect(resp *http.Response) (string, error) {
+	if p.format != "" {
+		if _, ok := decoders[p.format]; !ok {
+			return "", fmt.Errorf("%w: %q", errUnknownFormat, p.format)
+		}
+
+		return p.format, nil
+	}
+
+	mediaType, _, _ := mime.ParseMediaType(resp.Header.Get("Content-Type"))
+	if f, ok := mediaTypes[mediaType]; ok {
+		return f, nil
+	}
+	if strings.HasSuffix(mediaType, "+json") {
+		return FormatJSON, nil
+	}
+	if strings.HasSuffix(mediaType, "+yaml") {
+		return FormatYAML, nil
+	}
+
+	if f, ok := extensions[path.Ext(resp.Request.URL.Path)]; ok {
+		return f, nil
+	}
+
+	return "", fmt.Errorf("%w: content type %q", errUnknownFormat, mediaType)
+}
+
+func (p *Provider) readCache() (document, error) {
+	if p.cache == "" {
+		return document{}, os.ErrNotExist
+	}
+
+	var doc document
+
+	meta, err := os.ReadFile(p.cache + ".meta")
+	if err != nil {
+		return document{}, err
+	}
+	if err := json.Unmarshal(meta, &doc); err != nil {
+		return document{}, err
+	}
+
+	doc.Body, err = os.ReadFile(p.cache)
+	if err != nil {
+		return document{}, err
+	}
+
+	return doc, nil
+}
+
+func (p *Provider) writeCache(doc document) error {
+	if p.cache == "" {
+		return nil
+	}
+
+	meta, err := json.Marshal(doc)
+	if err != nil {
+		return err
+	}
+
+	if err := os.WriteFile(p.cache, doc.Body, 0o600); err != nil {
+		return err
+	}
+
+	return os.WriteFile(p.cache+".meta", meta, 0o600)
+}
+
+type statusError struct {
+	code int
+}
+
+func (e *statusError) Error() string {
+	return fmt.Sprintf("%s: %d %s", errStatus, e.code, http.StatusText(e.code))
+}
+
+func (e *statusError) Unwrap() error {
+	return errStatus
+}
+
+// fallback reports whether the cache is used on err: the service is unreachable or fails.
+// A cancelled request is not a failure of the service, the cache is not used.
+func fallback(err error) bool {
+	if errors.Is(err, context.Canceled) {
+		return false
+	}
+
+	var se *statusError
+	if errors.As(err, &se) {
+		return se.code >= http.StatusInternalServerError
+	}
+
+	return true
+}
+
+func redact(raw string) string {
+	u, err := url.Parse(raw)
+	if err != nil {
+		return raw
+	}
+
+	return u.Redacted()
+}
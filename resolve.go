@@ -0,0 +1,158 @@
+package zerocfg
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"sort"
+	"strings"
+)
+
+const schemeSep = "://"
+
+// Resolver returns the value a reference points to, ref is the part after "scheme://"
+// (e.g. "/run/secrets/db" for "file:///run/secrets/db").
+//
+// Errors must not contain the resolved value.
+type Resolver func(ref string) (string, error)
+
+var resolvers = map[string]Resolver{
+	"file": FileResolver,
+	"env":  EnvResolver,
+}
+
+// reservedSchemes are references even without a registered resolver,
+// so they fail with ErrNoResolver instead of being set as literal values.
+var reservedSchemes = map[string]bool{
+	"exec": true,
+}
+
+// RegisterResolver registers a Resolver for references with the given scheme, replacing an existing one.
+// Values of other schemes (e.g. "https://...") are not references and are set as is,
+// except "exec" references, which fail with ErrNoResolver until ExecResolver is registered.
+//
+// Example:
+//
+//	zfg.RegisterResolver("exec", zfg.ExecResolver)
+func RegisterResolver(scheme string, r Resolver) {
+	resolvers[scheme] = r
+}
+
+// Resolvable returns an OptNode that allows a configuration option to be set by a reference
+// instead of a literal value, e.g. "file:///run/secrets/db" or "env://LEGACY_PASS".
+// Secret options are resolvable too.
+//
+// References are resolved with registered resolvers (see RegisterResolver) after all providers
+// are applied. Resolved values are masked in rendered output as secrets.
+//
+// Example:
+//
+//	token := Str("api.token", "", "api token", Resolvable())
+func Resolvable() OptNode {
+	return func(n *node) {
+		n.isResolvable = true
+	}
+}
+
+// FileResolver reads the value from a file, trailing newlines are trimmed.
+// It is registered for the "file" scheme.
+func FileResolver(path string) (string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+
+	return strings.TrimRight(string(data), "\r\n"), nil
+}
+
+// EnvResolver reads the value from an environment variable, unset variable is an error.
+// It is registered for the "env" scheme.
+func EnvResolver(name string) (string, error) {
+	v, ok := os.LookupEnv(name)
+	if !ok {
+		return "", fmt.Errorf("variable %q is not set", name)
+	}
+
+	return v, nil
+}
+
+// ExecResolver runs a command and returns its output, trailing newlines are trimmed.
+// The reference is split into the command and its arguments by spaces, e.g. "exec://pass show db".
+//
+// It is not registered by default: anyone able to set a value of a resolvable option
+// could run commands, register it for the "exec" scheme if all configuration sources are trusted.
+// Until then, "exec://" values of resolvable options fail with ErrNoResolver.
+func ExecResolver(command string) (string, error) {
+	args := strings.Fields(command)
+	if len(args) == 0 {
+		return "", errors.New("empty command")
+	}
+
+	var stderr bytes.Buffer
+	cmd := exec.Command(args[0], args[1:]...)
+	cmd.Stderr = &stderr
+
+	out, err := cmd.Output()
+	if err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return "", fmt.Errorf("run %q: %w: %s", args[0], err, msg)
+		}
+
+		return "", fmt.Errorf("run %q: %w", args[0], err)
+	}
+
+	return strings.TrimRight(string(out), "\r\n"), nil
+}
+
+// reference splits a value into a registered or reserved scheme and a reference.
+func reference(v string) (scheme, ref string, ok bool) {
+	scheme, ref, found := strings.Cut(v, schemeSep)
+	if !found {
+		return "", "", false
+	}
+
+	if _, registered := resolvers[scheme]; !registered && !reservedSchemes[scheme] {
+		return "", "", false
+	}
+
+	return scheme, ref, true
+}
+
+// resolve sets options holding references to the values they point to.
+// Errors name the option and the scheme, never the resolved value.
+func (c *config) resolve() error {
+	names := make([]string, 0)
+	for name, n := range c.vs {
+		if n.ref != "" {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		n := c.vs[name]
+		scheme, ref, _ := reference(n.ref)
+
+		r, ok := resolvers[scheme]
+		if !ok {
+			return fmt.Errorf("resolve key=%q from %s reference: %w", name, scheme, ErrNoResolver)
+		}
+
+		v, err := r(ref)
+		if err != nil {
+			return fmt.Errorf("resolve key=%q from %s reference: %w", name, scheme, err)
+		}
+
+		n.ref = ""
+		n.isResolved = true
+
+		// the error of Set may contain the value
+		if err := n.Value.Set(v); err != nil {
+			return fmt.Errorf("resolve key=%q from %s reference: %w of type %q", name, scheme, ErrResolvedValue, n.Value.Type())
+		}
+	}
+
+	return nil
+}
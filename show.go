@@ -9,6 +9,7 @@ import (
 )
 
 // Show returns a formatted string representation of all registered configuration options and their current values.
+// Options set by a configuration source are annotated with its name, e.g. "database port [env]".
 func Show() string {
 	vs := make([]*node, 0, len(c.vs))
 	for _, n := range c.vs {
@@ -69,7 +70,7 @@ func setNestedValue(config map[string]any, path string, value any) {
 }
 
 func yamlConfigValue(n *node) any {
-	if n.isSecret {
+	if n.masked() {
 		return "<secret>"
 	}
 
@@ -129,11 +130,19 @@ func addNode(root *yaml.Node, path string, value any, comment string) {
 }
 
 func yamlDescription(n *node) string {
-	return n.Description
+	if n.setSource == "" {
+		return n.Description
+	}
+
+	if n.Description == "" {
+		return "[" + n.setSource + "]"
+	}
+
+	return n.Description + " [" + n.setSource + "]"
 }
 
 func yamlValue(n *node) string {
-	if n.isSecret {
+	if n.masked() {
 		return "<secret>"
 	}
 
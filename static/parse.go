@@ -0,0 +1,104 @@
+package static
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+
+	"github.com/chaindead/zerocfg/util"
+)
+
+var errDuplicate = errors.New("duplicate key")
+
+type Provider struct {
+	values map[string]any
+}
+
+// New creates a Provider with values set from code, e.g. for tests or embedding applications.
+//
+// Keys may be dotted option names, nested maps or a mix of both:
+//
+//	static.New(map[string]any{
+//	    "db.host": "localhost",
+//	    "db":      map[string]any{"port": 5432},
+//	})
+//
+// The same key given in both forms (e.g. "db.host" and "host" of "db") is an error of Provide.
+func New(values map[string]any) *Provider {
+	return &Provider{values: values}
+}
+
+func (p *Provider) Type() string {
+	return "static"
+}
+
+func (p *Provider) Provide(keys map[string]bool, conv func(any) string) (found, unknown map[string]string, err error) {
+	values, err := normalize(p.values)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	found, unknown = util.Flatten(values, keys, conv)
+
+	return found, unknown, nil
+}
+
+// normalize converts nested maps with string keys of any type (e.g. map[string]int) to map[string]any.
+// A key given both as a dotted key and in a nested map (e.g. "db.host" and "host" of "db") is an error.
+func normalize(m map[string]any) (map[string]any, error) {
+	out := make(map[string]any, len(m))
+	for k, v := range m {
+		out[k] = normalizeValue(v)
+	}
+
+	if err := checkDuplicates(out, "", make(map[string]bool)); err != nil {
+		return nil, err
+	}
+
+	return out, nil
+}
+
+// checkDuplicates returns an error if a dotted path of m (including paths of nested maps) is seen twice.
+func checkDuplicates(m map[string]any, prefix string, seen map[string]bool) error {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	for _, k := range keys {
+		path := k
+		if prefix != "" {
+			path = prefix + "." + k
+		}
+
+		if seen[path] {
+			return fmt.Errorf("%w %q", errDuplicate, path)
+		}
+		seen[path] = true
+
+		if sub, ok := m[k].(map[string]any); ok {
+			if err := checkDuplicates(sub, path, seen); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+func normalizeValue(v any) any {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Map || rv.Type().Key().Kind() != reflect.String {
+		return v
+	}
+
+	m := make(map[string]any, rv.Len())
+	iter := rv.MapRange()
+	for iter.Next() {
+		m[iter.Key().String()] = normalizeValue(iter.Value().Interface())
+	}
+
+	return m
+}
@@ -0,0 +1,110 @@
+package static_test
+
+import (
+	"testing"
+	"time"
+
+	zfg "github.com/chaindead/zerocfg"
+	"github.com/chaindead/zerocfg/static"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name    string
+		values  map[string]any
+		awaited map[string]bool
+		found   map[string]string
+		unknown map[string]string
+	}{
+		{
+			name:    "flat",
+			values:  map[string]any{"db.host": "localhost", "db.port": 5432, "stray": true},
+			awaited: map[string]bool{"db.host": true, "db.port": true},
+			found:   map[string]string{"db.host": "localhost", "db.port": "5432"},
+			unknown: map[string]string{"stray": "true"},
+		},
+		{
+			name: "nested",
+			values: map[string]any{
+				"db": map[string]any{
+					"host": "localhost",
+					"pool": map[string]int{"size": 10},
+				},
+			},
+			awaited: map[string]bool{"db.host": true, "db.pool.size": true},
+			found:   map[string]string{"db.host": "localhost", "db.pool.size": "10"},
+		},
+		{
+			name: "mixed",
+			values: map[string]any{
+				"db.host": "localhost",
+				"db":      map[string]any{"port": 5432},
+			},
+			awaited: map[string]bool{"db.host": true, "db.port": true},
+			found:   map[string]string{"db.host": "localhost", "db.port": "5432"},
+		},
+		{
+			name:    "nil",
+			values:  map[string]any{"db.host": nil, "db": map[string]any{"port": nil}, "stray": nil},
+			awaited: map[string]bool{"db.host": true, "db.port": true},
+			found:   map[string]string{},
+		},
+		{
+			name: "complex values",
+			values: map[string]any{
+				"tags":    []string{"a", "b"},
+				"limits":  map[string]int{"max": 10},
+				"timeout": 5 * time.Second,
+			},
+			awaited: map[string]bool{"tags": true, "limits": true, "timeout": true},
+			found:   map[string]string{"tags": `["a","b"]`, "limits": `{"max":10}`, "timeout": "5s"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.unknown == nil {
+				tt.unknown = map[string]string{}
+			}
+
+			found, unknown, err := static.New(tt.values).Provide(tt.awaited, zfg.ToString)
+			require.NoError(t, err)
+
+			assert.Equal(t, tt.found, found)
+			assert.Equal(t, tt.unknown, unknown)
+		})
+	}
+}
+
+func TestParse_Duplicate(t *testing.T) {
+	tests := []struct {
+		name   string
+		values map[string]any
+		err    string
+	}{
+		{
+			name:   "flat and nested",
+			values: map[string]any{"db.host": "a", "db": map[string]any{"host": "b"}},
+			err:    `duplicate key "db.host"`,
+		},
+		{
+			name:   "flat and nested map",
+			values: map[string]any{"db.pool": map[string]int{"size": 1}, "db": map[string]any{"pool": map[string]any{"size": 2}}},
+			err:    `duplicate key "db.pool"`,
+		},
+		{
+			name:   "inside nested map",
+			values: map[string]any{"db": map[string]any{"x.y": 1, "x": map[string]any{"y": 2}}},
+			err:    `duplicate key "db.x.y"`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, _, err := static.New(tt.values).Provide(map[string]bool{"db.host": true}, zfg.ToString)
+			require.EqualError(t, err, tt.err)
+		})
+	}
+}
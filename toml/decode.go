@@ -0,0 +1,713 @@
+package toml
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+var errSyntax = errors.New("syntax error")
+
+// datetime is a native toml date and/or time value.
+// It is rendered in RFC 3339 (or its local date and time parts), as expected by Time options.
+type datetime struct {
+	t      time.Time
+	layout string
+}
+
+func (d datetime) String() string {
+	return d.t.Format(d.layout)
+}
+
+func (d datetime) MarshalText() ([]byte, error) {
+	return []byte(d.String()), nil
+}
+
+var datetimeLayouts = []struct {
+	parse, format string
+}{
+	{"2006-01-02T15:04:05Z07:00", time.RFC3339Nano},
+	{"2006-01-02T15:04:05", "2006-01-02T15:04:05.999999999"},
+	{"2006-01-02", "2006-01-02"},
+	{"15:04:05", "15:04:05.999999999"},
+}
+
+// tableArray is an array of tables defined with [[name]] headers.
+type tableArray struct {
+	items []any
+}
+
+// decoder is a parser of toml documents into nested maps.
+//
+// Tables become map[string]any, arrays become []any, integers int64, floats float64
+// and dates and times datetime values.
+type decoder struct {
+	src  string
+	pos  int
+	line int
+
+	root    map[string]any
+	current map[string]any
+	prefix  []string
+	defined map[uintptr]bool // tables defined by headers
+	lines   map[string]int   // key path -> line
+}
+
+func decode(src string) (map[string]any, map[string]int, error) {
+	d := &decoder{
+		src:     src,
+		line:    1,
+		root:    make(map[string]any),
+		defined: make(map[uintptr]bool),
+		lines:   make(map[string]int),
+	}
+	d.current = d.root
+
+	if err := d.document(); err != nil {
+		return nil, nil, fmt.Errorf("line %d: %w", d.line, err)
+	}
+
+	return finalize(d.root).(map[string]any), d.lines, nil
+}
+
+func (d *decoder) document() error {
+	for {
+		d.skipBlank(true)
+		if d.eof() {
+			return nil
+		}
+
+		var err error
+		switch {
+		case strings.HasPrefix(d.rest(), "[["):
+			err = d.arrayTableHeader()
+		case d.peek() == '[':
+			err = d.tableHeader()
+		default:
+			err = d.keyValue(d.current, d.prefix)
+		}
+		if err != nil {
+			return err
+		}
+
+		if err := d.endOfLine(); err != nil {
+			return err
+		}
+	}
+}
+
+func (d *decoder) tableHeader() error {
+	line := d.line
+	d.pos++
+
+	keys, err := d.key()
+	if err != nil {
+		return err
+	}
+
+	if !d.consume("]") {
+		return d.errorf("expected ']' after table name")
+	}
+
+	t, err := d.table(d.root, keys)
+	if err != nil {
+		return err
+	}
+
+	id := reflect.ValueOf(t).Pointer()
+	if d.defined[id] {
+		return d.errorf("table %q is already defined", strings.Join(keys, "."))
+	}
+	d.defined[id] = true
+
+	d.current, d.prefix = t, keys
+	d.lines[strings.Join(keys, ".")] = line
+
+	return nil
+}
+
+func (d *decoder) arrayTableHeader() error {
+	line := d.line
+	d.pos += 2
+
+	keys, err := d.key()
+	if err != nil {
+		return err
+	}
+
+	if !d.consume("]]") {
+		return d.errorf("expected ']]' after array of tables name")
+	}
+
+	parent, err := d.table(d.root, keys[:len(keys)-1])
+	if err != nil {
+		return err
+	}
+
+	name := keys[len(keys)-1]
+	t := make(map[string]any)
+
+	switch v := parent[name].(type) {
+	case nil:
+		parent[name] = &tableArray{items: []any{t}}
+	case *tableArray:
+		v.items = append(v.items, t)
+	default:
+		return d.errorf("key %q is not an array of tables", strings.Join(keys, "."))
+	}
+
+	d.current, d.prefix = t, keys
+	d.lines[strings.Join(keys, ".")] = line
+
+	return nil
+}
+
+// table returns a nested table of t, creating missing ones.
+// The last element of an array of tables is used when the path goes through it.
+func (d *decoder) table(t map[string]any, keys []string) (map[string]any, error) {
+	for i, k := range keys {
+		switch v := t[k].(type) {
+		case nil:
+			sub := make(map[string]any)
+			t[k] = sub
+			t = sub
+		case map[string]any:
+			t = v
+		case *tableArray:
+			t = v.items[len(v.items)-1].(map[string]any)
+		default:
+			return nil, d.errorf("key %q is not a table", strings.Join(keys[:i+1], "."))
+		}
+	}
+
+	return t, nil
+}
+
+func (d *decoder) keyValue(t map[string]any, prefix []string) error {
+	line := d.line
+
+	keys, err := d.key()
+	if err != nil {
+		return err
+	}
+
+	d.skipSpace()
+	if !d.consume("=") {
+		return d.errorf("expected '=' after key %q", strings.Join(keys, "."))
+	}
+	d.skipSpace()
+
+	v, err := d.value()
+	if err != nil {
+		return err
+	}
+
+	parent, err := d.table(t, keys[:len(keys)-1])
+	if err != nil {
+		return err
+	}
+
+	name := keys[len(keys)-1]
+	if _, ok := parent[name]; ok {
+		return d.errorf("duplicate key %q", strings.Join(keys, "."))
+	}
+	parent[name] = v
+
+	path := append(append([]string{}, prefix...), keys...)
+	for i := range path {
+		key := strings.Join(path[:i+1], ".")
+		if _, ok := d.lines[key]; !ok || i == len(path)-1 {
+			d.lines[key] = line
+		}
+	}
+
+	return nil
+}
+
+// key parses a dotted key of bare and quoted parts.
+func (d *decoder) key() ([]string, error) {
+	var keys []string
+	for {
+		d.skipSpace()
+
+		var (
+			k   string
+			err error
+		)
+		switch d.peek() {
+		case '"':
+			k, err = d.basicString()
+		case '\'':
+			k, err = d.literalString()
+		default:
+			start := d.pos
+			for !d.eof() && isBare(d.peek()) {
+				d.pos++
+			}
+			if start == d.pos {
+				return nil, d.errorf("expected key")
+			}
+			k = d.src[start:d.pos]
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		keys = append(keys, k)
+
+		d.skipSpace()
+		if !d.consume(".") {
+			return keys, nil
+		}
+	}
+}
+
+func (d *decoder) value() (any, error) {
+	rest := d.rest()
+	switch {
+	case strings.HasPrefix(rest, `"""`):
+		return d.multilineBasicString()
+	case strings.HasPrefix(rest, `'''`):
+		return d.multilineLiteralString()
+	case d.peek() == '"':
+		return d.basicString()
+	case d.peek() == '\'':
+		return d.literalString()
+	case d.peek() == '[':
+		return d.array()
+	case d.peek() == '{':
+		return d.inlineTable()
+	}
+
+	return d.scalar()
+}
+
+func (d *decoder) scalar() (any, error) {
+	start := d.pos
+	for !d.eof() && isScalar(d.peek()) {
+		d.pos++
+	}
+
+	// date and time may be separated with a space
+	if d.pos-start == 10 && d.src[start+4] == '-' && strings.HasPrefix(d.rest(), " ") &&
+		len(d.rest()) > 3 && isDigit(d.src[d.pos+1]) && isDigit(d.src[d.pos+2]) && d.src[d.pos+3] == ':' {
+		d.pos++
+		for !d.eof() && isScalar(d.peek()) {
+			d.pos++
+		}
+	}
+
+	tok := d.src[start:d.pos]
+	switch tok {
+	case "":
+		return nil, d.errorf("expected value")
+	case "true":
+		return true, nil
+	case "false":
+		return false, nil
+	case "inf", "+inf":
+		return math.Inf(1), nil
+	case "-inf":
+		return math.Inf(-1), nil
+	case "nan", "+nan", "-nan":
+		return math.NaN(), nil
+	}
+
+	if strings.Contains(tok, ":") || (len(tok) >= 10 && tok[4] == '-') {
+		return d.datetime(tok)
+	}
+
+	return d.number(tok)
+}
+
+func (d *decoder) datetime(tok string) (any, error) {
+	norm := strings.ToUpper(strings.Replace(tok, " ", "T", 1))
+
+	for _, l := range datetimeLayouts {
+		t, err := time.Parse(l.parse, norm)
+		if err == nil {
+			return datetime{t: t, layout: l.format}, nil
+		}
+	}
+
+	return nil, d.errorf("invalid date or time %q", tok)
+}
+
+func (d *decoder) number(tok string) (any, error) {
+	if strings.HasPrefix(tok, "_") || strings.HasSuffix(tok, "_") || strings.Contains(tok, "__") {
+		return nil, d.errorf("invalid number %q", tok)
+	}
+	s := strings.ReplaceAll(tok, "_", "")
+
+	if len(s) > 2 && s[0] == '0' {
+		base := 0
+		switch s[1] {
+		case 'x':
+			base = 16
+		case 'o':
+			base = 8
+		case 'b':
+			base = 2
+		}
+
+		if base != 0 {
+			v, err := strconv.ParseInt(s[2:], base, 64)
+			if err != nil {
+				return nil, d.errorf("invalid number %q", tok)
+			}
+
+			return v, nil
+		}
+	}
+
+	if strings.ContainsAny(s, ".eE") {
+		v, err := strconv.ParseFloat(s, 64)
+		if err != nil {
+			return nil, d.errorf("invalid number %q", tok)
+		}
+
+		return v, nil
+	}
+
+	v, err := strconv.ParseInt(s, 10, 64)
+	if err != nil {
+		return nil, d.errorf("invalid value %q", tok)
+	}
+
+	return v, nil
+}
+
+func (d *decoder) array() (any, error) {
+	d.pos++
+
+	arr := make([]any, 0)
+	for {
+		d.skipBlank(true)
+		if d.consume("]") {
+			return arr, nil
+		}
+
+		v, err := d.value()
+		if err != nil {
+			return nil, err
+		}
+		arr = append(arr, v)
+
+		d.skipBlank(true)
+		if d.consume("]") {
+			return arr, nil
+		}
+		if !d.consume(",") {
+			return nil, d.errorf("expected ',' or ']' in array")
+		}
+	}
+}
+
+func (d *decoder) inlineTable() (any, error) {
+	d.pos++
+
+	t := make(map[string]any)
+	d.skipSpace()
+	if d.consume("}") {
+		return t, nil
+	}
+
+	for {
+		if err := d.inlineKeyValue(t); err != nil {
+			return nil, err
+		}
+
+		d.skipSpace()
+		if d.consume("}") {
+			return t, nil
+		}
+		if !d.consume(",") {
+			return nil, d.errorf("expected ',' or '}' in inline table")
+		}
+	}
+}
+
+// inlineKeyValue parses a key/value pair of an inline table, its lines are not tracked.
+func (d *decoder) inlineKeyValue(t map[string]any) error {
+	lines := d.lines
+	d.lines = make(map[string]int)
+	defer func() { d.lines = lines }()
+
+	return d.keyValue(t, nil)
+}
+
+func (d *decoder) basicString() (string, error) {
+	d.pos++
+
+	var b strings.Builder
+	for {
+		if d.eof() || d.peek() == '\n' {
+			return "", d.errorf("unterminated string")
+		}
+
+		c := d.next()
+		switch c {
+		case '"':
+			return b.String(), nil
+		case '\\':
+			if err := d.escape(&b); err != nil {
+				return "", err
+			}
+		default:
+			b.WriteByte(c)
+		}
+	}
+}
+
+func (d *decoder) multilineBasicString() (string, error) {
+	d.pos += 3
+	d.skipNewline()
+
+	var b strings.Builder
+	for {
+		if d.eof() {
+			return "", d.errorf("unterminated multi-line string")
+		}
+
+		if strings.HasPrefix(d.rest(), `"""`) {
+			d.pos += 3
+			// up to two quotes are allowed right before the closing delimiter
+			for i := 0; i < 2 && d.peek() == '"'; i++ {
+				b.WriteByte(d.next())
+			}
+
+			return b.String(), nil
+		}
+
+		c := d.next()
+		if c != '\\' {
+			b.WriteByte(c)
+
+			continue
+		}
+
+		// line ending backslash trims all whitespace up to the next non-whitespace character
+		if trimmed := strings.TrimLeft(d.rest(), " \t\r"); strings.HasPrefix(trimmed, "\n") {
+			d.pos = len(d.src) - len(trimmed)
+			d.skipBlank(false)
+
+			continue
+		}
+
+		if err := d.escape(&b); err != nil {
+			return "", err
+		}
+	}
+}
+
+func (d *decoder) literalString() (string, error) {
+	d.pos++
+
+	end := strings.IndexAny(d.rest(), "'\n")
+	if end < 0 || d.src[d.pos+end] != '\'' {
+		return "", d.errorf("unterminated string")
+	}
+
+	s := d.src[d.pos : d.pos+end]
+	d.pos += end + 1
+
+	return s, nil
+}
+
+func (d *decoder) multilineLiteralString() (string, error) {
+	d.pos += 3
+	d.skipNewline()
+
+	end := strings.Index(d.rest(), `'''`)
+	if end < 0 {
+		return "", d.errorf("unterminated multi-line string")
+	}
+
+	// up to two quotes are allowed right before the closing delimiter
+	for i := 0; i < 2 && strings.HasPrefix(d.src[d.pos+end+1:], `'''`); i++ {
+		end++
+	}
+
+	s := d.src[d.pos : d.pos+end]
+	d.line += strings.Count(s, "\n")
+	d.pos += end + 3
+
+	return s, nil
+}
+
+func (d *decoder) escape(b *strings.Builder) error {
+	if d.eof() {
+		return d.errorf("unterminated escape sequence")
+	}
+
+	c := d.next()
+	switch c {
+	case 'b':
+		b.WriteByte('\b')
+	case 't':
+		b.WriteByte('\t')
+	case 'n':
+		b.WriteByte('\n')
+	case 'f':
+		b.WriteByte('\f')
+	case 'r':
+		b.WriteByte('\r')
+	case 'e':
+		b.WriteByte(0x1b)
+	case '"', '\\':
+		b.WriteByte(c)
+	case 'u', 'U':
+		n := 4
+		if c == 'U' {
+			n = 8
+		}
+
+		if len(d.rest()) < n {
+			return d.errorf("invalid unicode escape")
+		}
+
+		code, err := strconv.ParseUint(d.src[d.pos:d.pos+n], 16, 32)
+		if err != nil || !utf8.ValidRune(rune(code)) {
+			return d.errorf("invalid unicode escape %q", d.src[d.pos:d.pos+n])
+		}
+
+		b.WriteRune(rune(code))
+		d.pos += n
+	default:
+		return d.errorf("invalid escape sequence \\%c", c)
+	}
+
+	return nil
+}
+
+// endOfLine expects only whitespace and a comment up to the end of line.
+func (d *decoder) endOfLine() error {
+	d.skipSpace()
+	d.skipComment()
+
+	if d.eof() {
+		return nil
+	}
+
+	if !d.skipNewline() {
+		return d.errorf("expected end of line, found %q", d.peek())
+	}
+
+	return nil
+}
+
+// skipBlank skips whitespace, newlines and optionally comments.
+func (d *decoder) skipBlank(comments bool) {
+	for {
+		d.skipSpace()
+		if comments {
+			d.skipComment()
+		}
+
+		if !d.skipNewline() {
+			return
+		}
+	}
+}
+
+func (d *decoder) skipSpace() {
+	for !d.eof() && (d.peek() == ' ' || d.peek() == '\t') {
+		d.pos++
+	}
+}
+
+func (d *decoder) skipComment() {
+	if d.peek() != '#' {
+		return
+	}
+
+	for !d.eof() && d.peek() != '\n' {
+		d.pos++
+	}
+}
+
+func (d *decoder) skipNewline() bool {
+	switch {
+	case d.consume("\r\n"):
+	case d.consume("\n"):
+	default:
+		return false
+	}
+
+	d.line++
+	return true
+}
+
+func (d *decoder) consume(s string) bool {
+	if !strings.HasPrefix(d.rest(), s) {
+		return false
+	}
+
+	d.pos += len(s)
+	return true
+}
+
+func (d *decoder) next() byte {
+	c := d.src[d.pos]
+	d.pos++
+	if c == '\n' {
+		d.line++
+	}
+
+	return c
+}
+
+func (d *decoder) peek() byte {
+	if d.eof() {
+		return 0
+	}
+
+	return d.src[d.pos]
+}
+
+func (d *decoder) rest() string {
+	return d.src[d.pos:]
+}
+
+func (d *decoder) eof() bool {
+	return d.pos >= len(d.src)
+}
+
+func (d *decoder) errorf(format string, args ...any) error {
+	return fmt.Errorf("%w: %s", errSyntax, fmt.Sprintf(format, args...))
+}
+
+// finalize converts arrays of tables to plain arrays.
+func finalize(v any) any {
+	switch v := v.(type) {
+	case map[string]any:
+		for k, sub := range v {
+			v[k] = finalize(sub)
+		}
+	case *tableArray:
+		for i, item := range v.items {
+			v.items[i] = finalize(item)
+		}
+
+		return v.items
+	}
+
+	return v
+}
+
+func isBare(c byte) bool {
+	return c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || isDigit(c) || c == '_' || c == '-'
+}
+
+func isScalar(c byte) bool {
+	return isBare(c) || c == '+' || c == '.' || c == ':'
+}
+
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
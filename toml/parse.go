@@ -0,0 +1,79 @@
+package toml
+
+import (
+	"fmt"
+	"io/fs"
+
+	"github.com/chaindead/zerocfg/util"
+)
+
+type Opt func(*Provider)
+
+// WithFS returns an Opt that reads the file from fsys instead of the operating system,
+// e.g. from an embed.FS with default configs. The path is slash-separated and relative to the root of fsys.
+func WithFS(fsys fs.FS) Opt {
+	return func(p *Provider) {
+		p.fsys = fsys
+	}
+}
+
+type Provider struct {
+	path *string
+	fsys fs.FS
+
+	lines map[string]int
+}
+
+// New creates a Provider reading a toml file.
+//
+// Tables and dotted keys are mapped to dotted option names, arrays (including arrays of tables)
+// are passed as json and dates and times as RFC 3339 values, compatible with Time options.
+func New(path *string, opts ...Opt) *Provider {
+	p := &Provider{path: path}
+	for _, opt := range opts {
+		opt(p)
+	}
+
+	return p
+}
+
+func (p *Provider) Type() string {
+	return fmt.Sprintf("toml[%s]", util.ShortenPath(*p.path))
+}
+
+// Locate returns the position of a key as "file:line".
+func (p *Provider) Locate(key string) (string, bool) {
+	line, ok := p.lines[key]
+	if !ok {
+		return "", false
+	}
+
+	return fmt.Sprintf("%s:%d", *p.path, line), true
+}
+
+func (p *Provider) Provide(keys map[string]bool, conv func(any) string) (found, unknown map[string]string, err error) {
+	data, err := util.ReadFile(p.fsys, *p.path)
+	if err != nil {
+		return nil, nil, fmt.Errorf("read toml file: %w", err)
+	}
+
+	settings, lines, err := decode(string(data))
+	if err != nil {
+		return nil, nil, fmt.Errorf("unmarshal toml: %w", err)
+	}
+	p.lines = lines
+
+	found, unknown = util.Flatten(settings, keys, conv)
+
+	return found, unknown, nil
+}
+
+// Decode parses a toml document into nested settings, as used by Provider.
+func Decode(data []byte) (map[string]any, error) {
+	settings, _, err := decode(string(data))
+	if err != nil {
+		return nil, fmt.Errorf("unmarshal toml: %w", err)
+	}
+
+	return settings, nil
+}
@@ -0,0 +1,253 @@
+package toml_test
+
+import (
+	"os"
+	"testing"
+	"testing/fstest"
+
+	zfg "github.com/chaindead/zerocfg"
+	"github.com/chaindead/zerocfg/toml"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		awaited map[string]bool
+		found   map[string]string
+		unknown map[string]string
+	}{
+		{
+			name: "simple key-value",
+			input: `
+str = "name" # comment
+int = 1`,
+			awaited: map[string]bool{"str": true},
+			found:   map[string]string{"str": "name"},
+			unknown: map[string]string{"int": "1"},
+		},
+		{
+			name: "tables and dotted keys",
+			input: `
+host = "localhost"
+
+[database]
+port = 5432
+credentials.username = "admin"
+
+[database.pool]
+size = 10`,
+			awaited: map[string]bool{
+				"database.credentials.username": true,
+				"database.pool.size":            true,
+			},
+			found: map[string]string{
+				"database.credentials.username": "admin",
+				"database.pool.size":            "10",
+			},
+			unknown: map[string]string{
+				"host":          "localhost",
+				"database.port": "5432",
+			},
+		},
+		{
+			name: "quoted keys",
+			input: `
+"a.b" = 1
+[site."google.com"]
+'x' = 2`,
+			awaited: map[string]bool{"a.b": true, "site.google.com.x": true},
+			found:   map[string]string{"a.b": "1", "site.google.com.x": "2"},
+		},
+		{
+			name: "strings",
+			input: `
+basic = "tab\tquote\"unicode\u00e9"
+literal = 'C:\path'
+multi = """
+one \
+  two"""
+multiLiteral = '''
+raw\n'''`,
+			awaited: map[string]bool{"basic": true, "literal": true, "multi": true, "multiLiteral": true},
+			found: map[string]string{
+				"basic":        "tab\tquote\"unicodeé",
+				"literal":      `C:\path`,
+				"multi":        "one two",
+				"multiLiteral": `raw\n`,
+			},
+		},
+		{
+			name: "numbers",
+			input: `
+int = 1_000
+hex = 0xff
+oct = 0o17
+bin = 0b101
+neg = -5
+float = 6.626e-34
+inf = -inf
+bool = true`,
+			awaited: map[string]bool{"int": true, "hex": true, "oct": true, "bin": true, "neg": true, "float": true, "inf": true, "bool": true},
+			found: map[string]string{
+				"int":   "1000",
+				"hex":   "255",
+				"oct":   "15",
+				"bin":   "5",
+				"neg":   "-5",
+				"float": "6.626e-34",
+				"inf":   "-Inf",
+				"bool":  "true",
+			},
+		},
+		{
+			name: "datetime",
+			input: `
+odt = 1979-05-27T07:32:00.5-07:00
+space = 1979-05-27 07:32:00Z
+ldt = 1979-05-27T07:32:00
+ld = 1979-05-27
+lt = 07:32:00`,
+			awaited: map[string]bool{"odt": true, "space": true, "ldt": true, "ld": true, "lt": true},
+			found: map[string]string{
+				"odt":   "1979-05-27T07:32:00.5-07:00",
+				"space": "1979-05-27T07:32:00Z",
+				"ldt":   "1979-05-27T07:32:00",
+				"ld":    "1979-05-27",
+				"lt":    "07:32:00",
+			},
+		},
+		{
+			name: "arrays",
+			input: `
+tags = ["a", "b"]
+ids = [
+  1,
+  2, # comment
+]
+nested = [[1, 2], ["a"]]`,
+			awaited: map[string]bool{"tags": true, "ids": true, "nested": true},
+			found: map[string]string{
+				"tags":   `["a","b"]`,
+				"ids":    `[1,2]`,
+				"nested": `[[1,2],["a"]]`,
+			},
+		},
+		{
+			name: "inline table",
+			input: `
+limits = { max = 10, min = 1, nested.key = "v" }`,
+			awaited: map[string]bool{"limits": true},
+			found:   map[string]string{"limits": `{"max":10,"min":1,"nested":{"key":"v"}}`},
+		},
+		{
+			name: "array of tables",
+			input: `
+[[servers]]
+name = "a"
+since = 2020-01-01
+
+[servers.tls]
+enabled = true
+
+[[servers]]
+name = "b"`,
+			awaited: map[string]bool{"servers": true},
+			found: map[string]string{
+				"servers": `[{"name":"a","since":"2020-01-01","tls":{"enabled":true}},{"name":"b"}]`,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.found == nil {
+				tt.found = map[string]string{}
+			}
+			if tt.unknown == nil {
+				tt.unknown = map[string]string{}
+			}
+
+			name := tempFile(t, tt.input)
+			p := toml.New(&name)
+
+			found, unknown, err := p.Provide(tt.awaited, zfg.ToString)
+			require.NoError(t, err)
+
+			assert.Equal(t, tt.found, found)
+			assert.Equal(t, tt.unknown, unknown)
+		})
+	}
+}
+
+func TestParse_Error(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		err   string
+	}{
+		{name: "missing value", input: "a = 1\nb =", err: "line 2: syntax error: expected value"},
+		{name: "duplicate key", input: "a = 1\na = 2", err: `line 2: syntax error: duplicate key "a"`},
+		{name: "duplicate table", input: "[a]\nx = 1\n[a]", err: `line 3: syntax error: table "a" is already defined`},
+		{name: "not a table", input: "a = 1\n[a.b]", err: `line 2: syntax error: key "a" is not a table`},
+		{name: "unterminated string", input: "\n\na = \"abc", err: "line 3: syntax error: unterminated string"},
+		{name: "garbage after value", input: "a = 1 2", err: "line 1: syntax error: expected end of line"},
+		{name: "bad number", input: "a = 1__0", err: `invalid number "1__0"`},
+		{name: "bad date", input: "a = 1979-13-27", err: `invalid date or time "1979-13-27"`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			name := tempFile(t, tt.input)
+
+			_, _, err := toml.New(&name).Provide(map[string]bool{}, zfg.ToString)
+			require.ErrorContains(t, err, tt.err)
+		})
+	}
+}
+
+func TestLocate(t *testing.T) {
+	name := tempFile(t, `
+a = 1
+
+[db]
+port = 5432`)
+	p := toml.New(&name)
+
+	_, _, err := p.Provide(map[string]bool{}, zfg.ToString)
+	require.NoError(t, err)
+
+	loc, ok := p.Locate("db.port")
+	assert.True(t, ok)
+	assert.Equal(t, name+":5", loc)
+
+	loc, ok = p.Locate("db")
+	assert.True(t, ok)
+	assert.Equal(t, name+":4", loc)
+}
+
+func TestFS(t *testing.T) {
+	fsys := fstest.MapFS{
+		"config/default.toml": {Data: []byte("[db]\nhost = \"localhost\"")},
+	}
+
+	path := "config/default.toml"
+	found, _, err := toml.New(&path, toml.WithFS(fsys)).Provide(map[string]bool{"db.host": true}, zfg.ToString)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"db.host": "localhost"}, found)
+}
+
+func tempFile(t *testing.T, data string) string {
+	f, err := os.CreateTemp("", "tmpfile-")
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		require.NoError(t, f.Close())
+	})
+
+	_, err = f.WriteString(data)
+	require.NoError(t, err)
+
+	return f.Name()
+}
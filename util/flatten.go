@@ -0,0 +1,61 @@
+package util
+
+// Flatten converts nested settings to dotted keys.
+//
+// A key is found once it is awaited, its value is converted with conv as a whole (e.g. maps and slices).
+// Nested maps of keys that are not awaited are flattened further, other values are unknown.
+// Nil values (e.g. JSON null) are skipped as unset.
+func Flatten(settings map[string]any, awaited map[string]bool, conv func(any) string) (found, unknown map[string]string) {
+	found, unknown = make(map[string]string), make(map[string]string)
+
+	flattenDFS(settings, "", awaited, conv, found, unknown)
+
+	return found, unknown
+}
+
+func flattenDFS(m map[string]any, prefix string, awaited map[string]bool, conv func(any) string, found, unknown map[string]string) {
+	for k, v := range m {
+		if v == nil {
+			continue
+		}
+
+		newKey := k
+		if prefix != "" {
+			newKey = prefix + "." + k
+		}
+
+		if awaited[newKey] {
+			found[newKey] = conv(v)
+
+			continue
+		}
+
+		if subMap, ok := v.(map[string]any); ok {
+			flattenDFS(subMap, newKey, awaited, conv, found, unknown)
+
+			continue
+		}
+
+		unknown[newKey] = conv(v)
+	}
+}
+
+// Split divides flat settings into found (awaited) and unknown keys, values are converted with conv.
+// Nil values are skipped as unset.
+func Split[T any](settings map[string]T, awaited map[string]bool, conv func(any) string) (found, unknown map[string]string) {
+	found, unknown = make(map[string]string), make(map[string]string)
+
+	for k, v := range settings {
+		if any(v) == nil {
+			continue
+		}
+
+		if awaited[k] {
+			found[k] = conv(v)
+		} else {
+			unknown[k] = conv(v)
+		}
+	}
+
+	return found, unknown
+}
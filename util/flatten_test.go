@@ -0,0 +1,41 @@
+package util
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFlatten(t *testing.T) {
+	settings := map[string]any{
+		"a": 1,
+		"b": map[string]any{
+			"c": 2,
+			"d": map[string]any{"e": 3},
+		},
+		"m": map[string]any{"k": 4},
+		"n": nil,
+		"o": map[string]any{"p": nil},
+	}
+	awaited := map[string]bool{
+		"b.c": true,
+		"m":   true,
+		"n":   true,
+	}
+
+	found, unknown := Flatten(settings, awaited, func(v any) string { return fmt.Sprint(v) })
+
+	assert.Equal(t, map[string]string{"b.c": "2", "m": "map[k:4]"}, found)
+	assert.Equal(t, map[string]string{"a": "1", "b.d.e": "3"}, unknown)
+}
+
+func TestSplit(t *testing.T) {
+	settings := map[string]any{"a": 1, "b": "x", "c": nil}
+	awaited := map[string]bool{"a": true, "c": true, "p": false}
+
+	found, unknown := Split(settings, awaited, func(v any) string { return fmt.Sprint(v) })
+
+	assert.Equal(t, map[string]string{"a": "1"}, found)
+	assert.Equal(t, map[string]string{"b": "x"}, unknown)
+}
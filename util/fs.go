@@ -0,0 +1,83 @@
+package util
+
+import (
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+)
+
+// File system helpers read from fsys, or from the operating system if fsys is nil.
+// Paths of fs.FS are slash-separated and relative to its root, OS paths use filepath rules.
+
+// ReadFile reads the named file.
+func ReadFile(fsys fs.FS, name string) ([]byte, error) {
+	if fsys == nil {
+		return os.ReadFile(name)
+	}
+
+	return fs.ReadFile(fsys, name)
+}
+
+// ReadDir reads the named directory, returning its entries sorted by name.
+func ReadDir(fsys fs.FS, name string) ([]fs.DirEntry, error) {
+	if fsys == nil {
+		return os.ReadDir(name)
+	}
+
+	return fs.ReadDir(fsys, name)
+}
+
+// Stat returns file info of the named file, following symbolic links.
+func Stat(fsys fs.FS, name string) (fs.FileInfo, error) {
+	if fsys == nil {
+		return os.Stat(name)
+	}
+
+	return fs.Stat(fsys, name)
+}
+
+// Glob returns names of all files matching pattern in lexical order.
+func Glob(fsys fs.FS, pattern string) ([]string, error) {
+	if fsys == nil {
+		return filepath.Glob(pattern)
+	}
+
+	return fs.Glob(fsys, pattern)
+}
+
+// Join joins path elements.
+func Join(fsys fs.FS, elem ...string) string {
+	if fsys == nil {
+		return filepath.Join(elem...)
+	}
+
+	return path.Join(elem...)
+}
+
+// Dir returns all but the last element of a path.
+func Dir(fsys fs.FS, name string) string {
+	if fsys == nil {
+		return filepath.Dir(name)
+	}
+
+	return path.Dir(name)
+}
+
+// Clean returns the shortest path name equivalent to name.
+func Clean(fsys fs.FS, name string) string {
+	if fsys == nil {
+		return filepath.Clean(name)
+	}
+
+	return path.Clean(name)
+}
+
+// IsAbs reports whether a path is absolute, paths of fs.FS never are.
+func IsAbs(fsys fs.FS, name string) bool {
+	if fsys == nil {
+		return filepath.IsAbs(name)
+	}
+
+	return false
+}
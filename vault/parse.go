@@ -0,0 +1,525 @@
+package vault
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"reflect"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/chaindead/zerocfg/meta"
+	"github.com/chaindead/zerocfg/util"
+)
+
+const defaultTimeout = 10 * time.Second
+
+var (
+	errStatus   = errors.New("unexpected status")
+	errNotFound = errors.New("secret not found")
+)
+
+type Opt func(*Provider)
+
+// WithSecret returns an Opt that reads the secret at path ("mount/path", e.g. "secret/db").
+//
+// Mapping maps secret fields to option names, e.g. {"password": "db.password"}, fields missing from it are skipped.
+// With nil mapping every field sets the option of the same name.
+func WithSecret(path string, mapping map[string]string) Opt {
+	return func(p *Provider) {
+		p.secrets = append(p.secrets, secret{path: strings.Trim(path, "/"), mapping: mapping})
+	}
+}
+
+// WithKVVersion returns an Opt that sets the version of the KV secrets engine, 2 by default.
+func WithKVVersion(version int) Opt {
+	return func(p *Provider) {
+		p.version = version
+	}
+}
+
+// WithToken returns an Opt that authorizes requests with a token.
+func WithToken(token string) Opt {
+	return func(p *Provider) {
+		p.token = token
+	}
+}
+
+// WithAppRole returns an Opt that logs in with AppRole auth method mounted at "approle".
+func WithAppRole(roleID, secretID string) Opt {
+	return func(p *Provider) {
+		p.roleID, p.secretID = roleID, secretID
+	}
+}
+
+// WithNamespace returns an Opt that sets the namespace of requests (Vault Enterprise).
+func WithNamespace(namespace string) Opt {
+	return func(p *Provider) {
+		p.namespace = namespace
+	}
+}
+
+// WithTimeout returns an Opt that limits the duration of reading all secrets, 10 seconds by default.
+func WithTimeout(d time.Duration) Opt {
+	return func(p *Provider) {
+		p.timeout = d
+	}
+}
+
+// WithClient returns an Opt that sets the http client, e.g. trusting the CA certificate of the Vault server.
+func WithClient(client *http.Client) Opt {
+	return func(p *Provider) {
+		p.client = client
+	}
+}
+
+// Provider reads secrets of the KV secrets engine of HashiCorp Vault via the HTTP API.
+type Provider struct {
+	addr      *string
+	secrets   []secret
+	version   int
+	token     string
+	roleID    string
+	secretID  string
+	namespace string
+	timeout   time.Duration
+	client    *http.Client
+
+	mu          sync.Mutex
+	clientToken string
+	auth        lease
+	leases      map[string]lease // lease id -> lease
+	last        map[string]any
+	keys        map[string]string // option key -> "path#field"
+}
+
+type secret struct {
+	path    string
+	mapping map[string]string
+}
+
+type lease struct {
+	renewable bool
+	duration  time.Duration
+	obtained  time.Time
+}
+
+// renewAt returns the time a lease should be renewed, after two thirds of its duration.
+func (l lease) renewAt() time.Time {
+	return l.obtained.Add(l.duration * 2 / 3)
+}
+
+type authResponse struct {
+	Auth struct {
+		ClientToken   string `json:"client_token"`
+		LeaseDuration int    `json:"lease_duration"`
+		Renewable     bool   `json:"renewable"`
+	} `json:"auth"`
+}
+
+type secretResponse struct {
+	LeaseID       string          `json:"lease_id"`
+	LeaseDuration int             `json:"lease_duration"`
+	Renewable     bool            `json:"renewable"`
+	Data          json.RawMessage `json:"data"`
+}
+
+// New creates a Provider reading secrets set by WithSecret from Vault at addr (e.g. "https://127.0.0.1:8200").
+//
+//	vault.New(addr,
+//		vault.WithAppRole(roleID, secretID),
+//		vault.WithSecret("secret/db", map[string]string{"user": "db.user", "password": "db.password"}),
+//	)
+//
+// Secret values are passed to options as is, they are never included in errors.
+func New(addr *string, opts ...Opt) *Provider {
+	p := &Provider{
+		addr:    addr,
+		version: 2,
+		timeout: defaultTimeout,
+		client:  http.DefaultClient,
+	}
+
+	for _, opt := range opts {
+		opt(p)
+	}
+
+	return p
+}
+
+func (p *Provider) Type() string {
+	paths := make([]string, 0, len(p.secrets))
+	for _, s := range p.secrets {
+		paths = append(paths, s.path)
+	}
+
+	return fmt.Sprintf("vault[%s]", strings.Join(paths, ","))
+}
+
+// Locate returns the secret field of an option as "path#field".
+func (p *Provider) Locate(key string) (string, bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	k, ok := p.keys[key]
+	return k, ok
+}
+
+func (p *Provider) Provide(keys map[string]bool, conv func(any) string) (found, unknown map[string]string, err error) {
+	return p.provide(context.Background(), keys, conv)
+}
+
+// ProvideContext logs in (see WithAppRole) and reads all secrets with ctx.
+func (p *Provider) ProvideContext(ctx context.Context, options map[string]meta.Option, conv func(any) string) (found, unknown map[string]string, err error) {
+	return p.provide(ctx, meta.Awaited(options), conv)
+}
+
+func (p *Provider) provide(ctx context.Context, keys map[string]bool, conv func(any) string) (found, unknown map[string]string, err error) {
+	settings, err := p.fetch(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	found, unknown = util.Split(settings, keys, conv)
+
+	return found, unknown, nil
+}
+
+// Watch reads the secrets every interval and calls onChange when any of them changes, until ctx is done.
+// Meanwhile, the token and renewable secret leases are renewed after two thirds of their duration,
+// a token that fails to renew is replaced by a new AppRole login. Failed reads are retried on the next tick.
+func (p *Provider) Watch(ctx context.Context, interval time.Duration, onChange func()) error {
+	if err := p.lookupSelf(ctx); err != nil {
+		return err
+	}
+
+	next := time.Now().Add(interval)
+	for {
+		wait := time.Until(next)
+		if renew := time.Until(p.renewAt()); renew < wait {
+			wait = renew
+		}
+
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		case <-timer.C:
+		}
+
+		p.renew(ctx)
+
+		if time.Now().Before(next) {
+			continue
+		}
+		next = time.Now().Add(interval)
+
+		p.mu.Lock()
+		last := p.last
+		p.mu.Unlock()
+
+		settings, err := p.fetch(ctx)
+		if err != nil {
+			continue
+		}
+
+		if !reflect.DeepEqual(settings, last) {
+			onChange()
+		}
+	}
+}
+
+// fetch reads all secrets and maps their fields to options.
+func (p *Provider) fetch(ctx context.Context) (map[string]any, error) {
+	ctx, cancel := context.WithTimeout(ctx, p.timeout)
+	defer cancel()
+
+	if err := p.login(ctx); err != nil {
+		return nil, err
+	}
+
+	settings := make(map[string]any)
+	keys := make(map[string]string)
+	leases := make(map[string]lease)
+	for _, s := range p.secrets {
+		data, id, l, err := p.read(ctx, s.path)
+		if err != nil {
+			return nil, fmt.Errorf("read %q: %w", s.path, err)
+		}
+
+		for field, v := range data {
+			key := field
+			if s.mapping != nil {
+				var ok bool
+				if key, ok = s.mapping[field]; !ok {
+					continue
+				}
+			}
+
+			settings[key] = v
+			keys[key] = s.path + "#" + field
+		}
+
+		if id != "" {
+			leases[id] = l
+		}
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.last = settings
+	p.keys = keys
+	p.leases = leases
+
+	return settings, nil
+}
+
+// read reads fields of a secret, KV v2 paths get "data" after the mount.
+func (p *Provider) read(ctx context.Context, path string) (data map[string]any, leaseID string, l lease, err error) {
+	apiPath := path
+	if p.version == 2 {
+		mount, rest, _ := strings.Cut(path, "/")
+		apiPath = mount + "/data/" + rest
+	}
+
+	var resp secretResponse
+	if err := p.request(ctx, http.MethodGet, apiPath, nil, &resp); err != nil {
+		return nil, "", lease{}, err
+	}
+
+	raw := resp.Data
+	if p.version == 2 {
+		var v2 struct {
+			Data json.RawMessage `json:"data"`
+		}
+		if err := json.Unmarshal(raw, &v2); err != nil {
+			return nil, "", lease{}, fmt.Errorf("decode response: %w", err)
+		}
+		raw = v2.Data
+	}
+
+	dec := json.NewDecoder(bytes.NewReader(raw))
+	dec.UseNumber()
+	if err := dec.Decode(&data); err != nil {
+		return nil, "", lease{}, fmt.Errorf("decode response: %w", err)
+	}
+
+	l = lease{
+		renewable: resp.Renewable,
+		duration:  time.Duration(resp.LeaseDuration) * time.Second,
+		obtained:  time.Now(),
+	}
+
+	return data, resp.LeaseID, l, nil
+}
+
+// login obtains a token with AppRole unless a token is already known.
+func (p *Provider) login(ctx context.Context) error {
+	p.mu.Lock()
+	if p.clientToken == "" && p.roleID == "" {
+		p.clientToken = p.token
+	}
+	known := p.clientToken != "" || p.roleID == ""
+	p.mu.Unlock()
+
+	if known {
+		return nil
+	}
+
+	var resp authResponse
+	body := map[string]string{"role_id": p.roleID, "secret_id": p.secretID}
+	if err := p.request(ctx, http.MethodPost, "auth/approle/login", body, &resp); err != nil {
+		return fmt.Errorf("approle login: %w", err)
+	}
+
+	p.setAuth(resp)
+
+	return nil
+}
+
+// lookupSelf reads the lease of a static token, AppRole tokens are known from login.
+func (p *Provider) lookupSelf(ctx context.Context) error {
+	if err := p.login(ctx); err != nil {
+		return err
+	}
+
+	if p.roleID != "" {
+		return nil
+	}
+
+	var resp struct {
+		Data struct {
+			TTL       int  `json:"ttl"`
+			Renewable bool `json:"renewable"`
+		} `json:"data"`
+	}
+	if err := p.request(ctx, http.MethodGet, "auth/token/lookup-self", nil, &resp); err != nil {
+		return fmt.Errorf("lookup token: %w", err)
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.auth = lease{
+		renewable: resp.Data.Renewable,
+		duration:  time.Duration(resp.Data.TTL) * time.Second,
+		obtained:  time.Now(),
+	}
+
+	return nil
+}
+
+// renew renews the token and secret leases that are due.
+func (p *Provider) renew(ctx context.Context) {
+	ctx, cancel := context.WithTimeout(ctx, p.timeout)
+	defer cancel()
+
+	now := time.Now()
+
+	p.mu.Lock()
+	auth := p.auth
+	leases := make(map[string]lease, len(p.leases))
+	for id, l := range p.leases {
+		leases[id] = l
+	}
+	p.mu.Unlock()
+
+	if auth.renewable && !now.Before(auth.renewAt()) {
+		var resp authResponse
+		if err := p.request(ctx, http.MethodPost, "auth/token/renew-self", struct{}{}, &resp); err != nil {
+			p.mu.Lock()
+			p.auth = lease{}
+			if p.roleID != "" {
+				// login again on the next read
+				p.clientToken = ""
+			}
+			p.mu.Unlock()
+		} else {
+			p.setAuth(resp)
+		}
+	}
+
+	for id, l := range leases {
+		if !l.renewable || now.Before(l.renewAt()) {
+			continue
+		}
+
+		var resp secretResponse
+		err := p.request(ctx, http.MethodPut, "sys/leases/renew", map[string]string{"lease_id": id}, &resp)
+
+		p.mu.Lock()
+		if err != nil {
+			// the secret is read again on the next tick
+			delete(p.leases, id)
+		} else if _, ok := p.leases[id]; ok {
+			p.leases[id] = lease{
+				renewable: resp.Renewable,
+				duration:  time.Duration(resp.LeaseDuration) * time.Second,
+				obtained:  time.Now(),
+			}
+		}
+		p.mu.Unlock()
+	}
+}
+
+// renewAt returns the time of the next renewal, zero time if nothing is renewable.
+func (p *Provider) renewAt() time.Time {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	var at time.Time
+	if p.auth.renewable {
+		at = p.auth.renewAt()
+	}
+
+	for _, l := range p.leases {
+		if l.renewable && (at.IsZero() || l.renewAt().Before(at)) {
+			at = l.renewAt()
+		}
+	}
+
+	if at.IsZero() {
+		// far in the future, polling interval applies
+		return time.Now().Add(24 * time.Hour * 365)
+	}
+
+	return at
+}
+
+func (p *Provider) setAuth(resp authResponse) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.clientToken = resp.Auth.ClientToken
+	p.auth = lease{
+		renewable: resp.Auth.Renewable,
+		duration:  time.Duration(resp.Auth.LeaseDuration) * time.Second,
+		obtained:  time.Now(),
+	}
+}
+
+func (p *Provider) request(ctx context.Context, method, path string, body, out any) error {
+	var data []byte
+	if body != nil {
+		var err error
+		if data, err = json.Marshal(body); err != nil {
+			return fmt.Errorf("encode request: %w", err)
+		}
+	}
+
+	u := strings.TrimRight(*p.addr, "/") + "/v1/" + path
+	req, err := http.NewRequestWithContext(ctx, method, u, bytes.NewReader(data))
+	if err != nil {
+		return fmt.Errorf("create request: %w", err)
+	}
+
+	p.mu.Lock()
+	token := p.clientToken
+	p.mu.Unlock()
+
+	if token != "" {
+		req.Header.Set("X-Vault-Token", token)
+	}
+	if p.namespace != "" {
+		req.Header.Set("X-Vault-Namespace", p.namespace)
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("fetch: %w", err)
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+	case http.StatusNoContent:
+		return nil
+	case http.StatusNotFound:
+		return errNotFound
+	default:
+		var verr struct {
+			Errors []string `json:"errors"`
+		}
+		_ = json.NewDecoder(resp.Body).Decode(&verr)
+		if len(verr.Errors) == 0 {
+			return fmt.Errorf("%w: %s", errStatus, resp.Status)
+		}
+
+		return fmt.Errorf("%w: %s: %s", errStatus, resp.Status, strings.Join(verr.Errors, "; "))
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("decode response: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,288 @@
+package vault_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	zfg "github.com/chaindead/zerocfg"
+	"github.com/chaindead/zerocfg/vault"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeVault implements the subset of the Vault HTTP API used by the provider.
+type fakeVault struct {
+	mu      sync.Mutex
+	kv1     map[string]map[string]any
+	kv2     map[string]map[string]any
+	ttl     int
+	renewed atomic.Int32
+	leases  atomic.Int32
+}
+
+func newFake() *fakeVault {
+	return &fakeVault{
+		kv1: map[string]map[string]any{
+			"kv/app": {"port": 5432, "hosts": []string{"a", "b"}, "empty": nil},
+		},
+		kv2: map[string]map[string]any{
+			"secret/db": {"user": "admin", "password": "pa$$", "extra": "x"},
+		},
+		ttl: 3600,
+	}
+}
+
+func (f *fakeVault) set(path, field string, v any) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.kv2[path][field] = v
+}
+
+func (f *fakeVault) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	path := strings.TrimPrefix(r.URL.Path, "/v1/")
+
+	if path == "auth/approle/login" {
+		var req map[string]string
+		_ = json.NewDecoder(r.Body).Decode(&req)
+		if req["role_id"] != "role" || req["secret_id"] != "secret" {
+			w.WriteHeader(http.StatusBadRequest)
+			_, _ = w.Write([]byte(`{"errors":["invalid role or secret ID"]}`))
+			return
+		}
+
+		f.reply(w, map[string]any{"auth": map[string]any{"client_token": "approle-token", "lease_duration": f.ttl, "renewable": true}})
+		return
+	}
+
+	token := r.Header.Get("X-Vault-Token")
+	if token != "root" && token != "approle-token" {
+		w.WriteHeader(http.StatusForbidden)
+		_, _ = w.Write([]byte(`{"errors":["permission denied"]}`))
+		return
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	switch {
+	case path == "auth/token/lookup-self":
+		f.reply(w, map[string]any{"data": map[string]any{"ttl": f.ttl, "renewable": true}})
+	case path == "auth/token/renew-self":
+		f.renewed.Add(1)
+		f.reply(w, map[string]any{"auth": map[string]any{"client_token": token, "lease_duration": f.ttl, "renewable": true}})
+	case path == "sys/leases/renew":
+		f.leases.Add(1)
+		f.reply(w, map[string]any{"lease_id": "lease", "lease_duration": f.ttl, "renewable": true})
+	case f.kv1[path] != nil:
+		f.reply(w, map[string]any{"lease_duration": 2764800, "data": f.kv1[path]})
+	case strings.Contains(path, "/data/"):
+		data := f.kv2[strings.Replace(path, "/data/", "/", 1)]
+		if data == nil {
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = w.Write([]byte(`{"errors":[]}`))
+			return
+		}
+
+		f.reply(w, map[string]any{
+			"lease_id":       "lease",
+			"lease_duration": f.ttl,
+			"renewable":      true,
+			"data":           map[string]any{"data": data, "metadata": map[string]any{"version": 1}},
+		})
+	default:
+		w.WriteHeader(http.StatusNotFound)
+	}
+}
+
+func (f *fakeVault) reply(w http.ResponseWriter, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+func TestProvide(t *testing.T) {
+	tests := []struct {
+		name    string
+		opts    []vault.Opt
+		awaited map[string]bool
+		found   map[string]string
+		unknown map[string]string
+	}{
+		{
+			name: "kv2 with mapping",
+			opts: []vault.Opt{
+				vault.WithToken("root"),
+				vault.WithSecret("secret/db", map[string]string{"user": "db.user", "password": "db.password"}),
+			},
+			awaited: map[string]bool{"db.user": true, "db.password": true},
+			found:   map[string]string{"db.user": "admin", "db.password": "pa$$"},
+		},
+		{
+			name: "kv1 without mapping",
+			opts: []vault.Opt{
+				vault.WithToken("root"),
+				vault.WithKVVersion(1),
+				vault.WithSecret("kv/app", nil),
+			},
+			// null fields are skipped
+			awaited: map[string]bool{"port": true, "empty": true},
+			found:   map[string]string{"port": "5432"},
+			unknown: map[string]string{"hosts": `["a","b"]`},
+		},
+		{
+			name: "approle",
+			opts: []vault.Opt{
+				vault.WithAppRole("role", "secret"),
+				vault.WithSecret("/secret/db/", map[string]string{"password": "db.password"}),
+			},
+			awaited: map[string]bool{"db.password": true},
+			found:   map[string]string{"db.password": "pa$$"},
+		},
+		{
+			name: "several secrets",
+			opts: []vault.Opt{
+				vault.WithToken("root"),
+				vault.WithSecret("secret/db", map[string]string{"user": "db.user"}),
+				vault.WithSecret("secret/db", map[string]string{"password": "db.password"}),
+			},
+			awaited: map[string]bool{"db.user": true, "db.password": true},
+			found:   map[string]string{"db.user": "admin", "db.password": "pa$$"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.unknown == nil {
+				tt.unknown = map[string]string{}
+			}
+
+			srv := httptest.NewServer(newFake())
+			defer srv.Close()
+
+			p := vault.New(&srv.URL, tt.opts...)
+
+			found, unknown, err := p.Provide(tt.awaited, zfg.ToString)
+			require.NoError(t, err)
+			assert.Equal(t, tt.found, found)
+			assert.Equal(t, tt.unknown, unknown)
+		})
+	}
+}
+
+func TestProvide_Errors(t *testing.T) {
+	tests := []struct {
+		name string
+		opts []vault.Opt
+		err  string
+	}{
+		{
+			name: "permission denied",
+			opts: []vault.Opt{vault.WithToken("wrong"), vault.WithSecret("secret/db", nil)},
+			err:  `read "secret/db": unexpected status: 403 Forbidden: permission denied`,
+		},
+		{
+			name: "approle login",
+			opts: []vault.Opt{vault.WithAppRole("role", "wrong"), vault.WithSecret("secret/db", nil)},
+			err:  "approle login: unexpected status: 400 Bad Request: invalid role or secret ID",
+		},
+		{
+			name: "missing secret",
+			opts: []vault.Opt{vault.WithToken("root"), vault.WithSecret("secret/missing", nil)},
+			err:  `read "secret/missing": secret not found`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := httptest.NewServer(newFake())
+			defer srv.Close()
+
+			_, _, err := vault.New(&srv.URL, tt.opts...).Provide(map[string]bool{}, zfg.ToString)
+			require.Error(t, err)
+			assert.Equal(t, tt.err, err.Error())
+		})
+	}
+}
+
+func TestLocate(t *testing.T) {
+	srv := httptest.NewServer(newFake())
+	defer srv.Close()
+
+	p := vault.New(&srv.URL, vault.WithToken("root"), vault.WithSecret("secret/db", map[string]string{"password": "db.password"}))
+
+	_, _, err := p.Provide(map[string]bool{}, zfg.ToString)
+	require.NoError(t, err)
+
+	loc, ok := p.Locate("db.password")
+	require.True(t, ok)
+	assert.Equal(t, "secret/db#password", loc)
+	assert.Equal(t, "vault[secret/db]", p.Type())
+}
+
+func TestProvideContext(t *testing.T) {
+	srv := httptest.NewServer(newFake())
+	defer srv.Close()
+
+	options := map[string]zfg.Option{"db.password": {Name: "db.password", Type: "string", IsSecret: true}}
+	newProvider := func() *vault.Provider {
+		return vault.New(&srv.URL, vault.WithToken("root"), vault.WithSecret("secret/db", map[string]string{"password": "db.password"}))
+	}
+
+	found, _, err := newProvider().ProvideContext(context.Background(), options, zfg.ToString)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"db.password": "pa$$"}, found)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, _, err = newProvider().ProvideContext(ctx, options, zfg.ToString)
+	require.ErrorIs(t, err, context.Canceled)
+}
+
+func TestWatch(t *testing.T) {
+	fake := newFake()
+	fake.ttl = 1
+
+	srv := httptest.NewServer(fake)
+	defer srv.Close()
+
+	p := vault.New(&srv.URL, vault.WithToken("root"), vault.WithSecret("secret/db", map[string]string{"password": "db.password"}))
+
+	_, _, err := p.Provide(map[string]bool{}, zfg.ToString)
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	changed := make(chan struct{}, 1)
+	go func() {
+		_ = p.Watch(ctx, time.Second, func() { changed <- struct{}{} })
+	}()
+
+	// unmapped field does not change options
+	fake.set("secret/db", "extra", "y")
+
+	select {
+	case <-changed:
+		t.Fatal("unexpected change")
+	case <-time.After(1200 * time.Millisecond):
+	}
+
+	fake.set("secret/db", "password", "new")
+
+	select {
+	case <-changed:
+	case <-ctx.Done():
+		t.Fatal("change not detected")
+	}
+
+	// token and lease of 1s are renewed after 2/3 of it, before the next read
+	assert.Positive(t, fake.renewed.Load())
+	assert.Positive(t, fake.leases.Load())
+}
@@ -0,0 +1,96 @@
+package yaml
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+var (
+	errUndefinedVar = errors.New("undefined variable")
+	errUnterminated = errors.New("unterminated placeholder")
+)
+
+// expandNode expands environment placeholders in all scalar values of n, keys are left as is.
+func (p *Provider) expandNode(n *yaml.Node, path string) error {
+	switch n.Kind {
+	case yaml.MappingNode:
+		for i := 1; i < len(n.Content); i += 2 {
+			if err := p.expandNode(n.Content[i], path); err != nil {
+				return err
+			}
+		}
+	case yaml.SequenceNode:
+		for _, c := range n.Content {
+			if err := p.expandNode(c, path); err != nil {
+				return err
+			}
+		}
+	case yaml.ScalarNode:
+		if !strings.Contains(n.Value, "$") {
+			return nil
+		}
+
+		v, err := expand(n.Value)
+		if err != nil {
+			return fmt.Errorf("expand env at %s: %w", p.position(n, path), err)
+		}
+
+		n.Value = v
+		// expanded values are strings as in the environment, options parse them by type
+		n.Tag = "!!str"
+	}
+
+	return nil
+}
+
+// expand replaces ${NAME} and ${NAME:-default} with environment values, "$$" is an escaped "$".
+func expand(s string) (string, error) {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] != '$' || i+1 == len(s) {
+			b.WriteByte(s[i])
+			continue
+		}
+
+		switch s[i+1] {
+		case '$':
+			b.WriteByte('$')
+			i++
+		case '{':
+			end := strings.IndexByte(s[i:], '}')
+			if end < 0 {
+				return "", fmt.Errorf("%w: %q", errUnterminated, s[i:])
+			}
+
+			v, err := lookup(s[i+2 : i+end])
+			if err != nil {
+				return "", err
+			}
+
+			b.WriteString(v)
+			i += end
+		default:
+			b.WriteByte('$')
+		}
+	}
+
+	return b.String(), nil
+}
+
+func lookup(placeholder string) (string, error) {
+	name, def, hasDef := strings.Cut(placeholder, ":-")
+
+	v, ok := os.LookupEnv(name)
+	if hasDef && v == "" {
+		return def, nil
+	}
+	if !ok {
+		return "", fmt.Errorf("%w %q", errUndefinedVar, name)
+	}
+
+	return v, nil
+}
@@ -0,0 +1,158 @@
+package yaml
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/chaindead/zerocfg/util"
+	"gopkg.in/yaml.v3"
+)
+
+const includeTag = "!include"
+
+var (
+	errIncludeCycle = errors.New("include cycle")
+	errNotMapping   = errors.New("included file is not a mapping")
+)
+
+// resolveIncludes replaces nodes tagged with !include by the content of included files.
+// The chain holds files being included, starting from the root one.
+func (p *Provider) resolveIncludes(n *yaml.Node, file string, chain []string) (*yaml.Node, error) {
+	if n.Tag == includeTag {
+		return p.include(n, file, chain)
+	}
+
+	for i, c := range n.Content {
+		r, err := p.resolveIncludes(c, file, chain)
+		if err != nil {
+			return nil, err
+		}
+
+		n.Content[i] = r
+	}
+
+	return n, nil
+}
+
+// include resolves a single !include node, path is relative to the including file.
+// Glob patterns include every matching file in lexical order, merged as with NewMerge.
+func (p *Provider) include(n *yaml.Node, from string, chain []string) (*yaml.Node, error) {
+	wrap := func(err error) error {
+		return fmt.Errorf("%s: include %q: %w", p.position(n, from), n.Value, err)
+	}
+
+	pattern := n.Value
+	if !util.IsAbs(p.fsys, pattern) {
+		pattern = util.Join(p.fsys, util.Dir(p.fsys, from), pattern)
+	}
+
+	if !strings.ContainsAny(pattern, `*?[`) {
+		inc, err := p.includeFile(pattern, chain)
+		if err != nil {
+			return nil, wrap(err)
+		}
+
+		return inc, nil
+	}
+
+	matches, err := util.Glob(p.fsys, pattern)
+	if err != nil {
+		return nil, wrap(err)
+	}
+
+	merged := &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map", Line: n.Line, Column: n.Column}
+	for _, m := range matches {
+		inc, err := p.includeFile(m, chain)
+		if err != nil {
+			return nil, wrap(err)
+		}
+
+		if inc.Kind != yaml.MappingNode {
+			return nil, wrap(fmt.Errorf("%s: %w", m, errNotMapping))
+		}
+
+		p.mergeNodes(merged, inc)
+	}
+
+	return merged, nil
+}
+
+func (p *Provider) includeFile(path string, chain []string) (*yaml.Node, error) {
+	path = util.Clean(p.fsys, path)
+
+	next := append(append([]string{}, chain...), path)
+	for _, c := range chain {
+		if c == path {
+			return nil, fmt.Errorf("%w: %s", errIncludeCycle, strings.Join(next, " -> "))
+		}
+	}
+
+	data, err := util.ReadFile(p.fsys, path)
+	if err != nil {
+		return nil, err
+	}
+
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+
+	if len(doc.Content) == 0 {
+		return &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}, nil
+	}
+
+	root, err := p.resolveIncludes(doc.Content[0], path, next)
+	if err != nil {
+		return nil, err
+	}
+
+	p.markFile(root, path)
+
+	return root, nil
+}
+
+// markFile records the file of nodes not yet attributed to a nested include.
+func (p *Provider) markFile(n *yaml.Node, path string) {
+	if _, ok := p.fileOf[n]; !ok {
+		p.fileOf[n] = path
+	}
+
+	for _, c := range n.Content {
+		p.markFile(c, path)
+	}
+}
+
+// mergeNodes deep-merges mapping node src into dst, following the same rules as merge.
+func (p *Provider) mergeNodes(dst, src *yaml.Node) {
+	for i := 0; i+1 < len(src.Content); i += 2 {
+		k, v := src.Content[i], src.Content[i+1]
+
+		j := findKey(dst, k.Value)
+		if j < 0 {
+			dst.Content = append(dst.Content, k, v)
+
+			continue
+		}
+
+		dv := dst.Content[j+1]
+		switch {
+		case dv.Kind == yaml.MappingNode && v.Kind == yaml.MappingNode:
+			p.mergeNodes(dv, v)
+		case dv.Kind == yaml.SequenceNode && v.Kind == yaml.SequenceNode && p.lists == ListAppend:
+			dv.Content = append(dv.Content, v.Content...)
+		default:
+			dst.Content[j+1] = v
+		}
+	}
+}
+
+func findKey(n *yaml.Node, key string) int {
+	for i := 0; i+1 < len(n.Content); i += 2 {
+		if n.Content[i].Value == key {
+			return i
+		}
+	}
+
+	return -1
+}
@@ -1,78 +1,330 @@
 package yaml
 
 import (
+	"errors"
 	"fmt"
-	"os"
+	"io/fs"
+	"sort"
+	"strings"
 
 	"github.com/chaindead/zerocfg/util"
 	"gopkg.in/yaml.v3"
 )
 
+// ListMerge defines how lists are merged when several files define the same key.
+type ListMerge int
+
+const (
+	// ListReplace replaces lists from earlier files with lists from later ones (default).
+	ListReplace ListMerge = iota
+	// ListAppend appends lists from later files to lists from earlier ones.
+	ListAppend
+)
+
+type Opt func(*Provider)
+
+// WithListMerge returns an Opt that sets how lists are merged across files.
+func WithListMerge(m ListMerge) Opt {
+	return func(p *Provider) {
+		p.lists = m
+	}
+}
+
+// Optional returns an Opt that makes missing files and empty paths yield no values instead of an error.
+// Other read errors (e.g. permission denied) and malformed yaml still fail.
+func Optional() Opt {
+	return func(p *Provider) {
+		p.optional = true
+	}
+}
+
+// WithPatterns returns an Opt that sets glob patterns of files read by NewDir, "*.yaml" and "*.yml" by default.
+func WithPatterns(patterns ...string) Opt {
+	return func(p *Provider) {
+		p.patterns = patterns
+	}
+}
+
+// WithFS returns an Opt that reads files (including includes) from fsys instead of the operating system,
+// e.g. from an embed.FS with default configs. Paths are slash-separated and relative to the root of fsys.
+func WithFS(fsys fs.FS) Opt {
+	return func(p *Provider) {
+		p.fsys = fsys
+	}
+}
+
+// ExpandEnv returns an Opt that expands environment variables in yaml values:
+//   - ${NAME} is replaced with the value of NAME, undefined NAME is an error
+//   - ${NAME:-default} is replaced with default if NAME is unset or empty
+//   - $$ is replaced with a literal $
+//
+// Expanded values are strings, e.g. "0123" is not read as a number, options parse them by type.
+func ExpandEnv() Opt {
+	return func(p *Provider) {
+		p.expandEnv = true
+	}
+}
+
 type Provider struct {
-	path *string
+	path      *string
+	paths     *[]string
+	dir       *string
+	patterns  []string
+	lists     ListMerge
+	optional  bool
+	expandEnv bool
+	fsys      fs.FS
 
-	conv    func(any) string
-	awaited map[string]bool
+	origin  map[string]string // key path -> position
+	keyFile map[string]string // key path -> file
+	fileOf  map[*yaml.Node]string
 }
 
-func New(path *string) *Provider {
-	return &Provider{path: path}
+// New creates a Provider reading a single yaml file.
+func New(path *string, opts ...Opt) *Provider {
+	return newProvider(&Provider{path: path}, opts)
+}
+
+// NewMerge creates a Provider reading several yaml files.
+//
+// Files are deep-merged in order before flattening, so later files override earlier ones:
+//
+//	files := []string{"base.yaml", "prod.yaml", "local.yaml"}
+//	yaml.NewMerge(&files, yaml.WithListMerge(yaml.ListAppend))
+//
+// Nested maps are merged key by key, lists are merged according to WithListMerge
+// and all other values are replaced. Unknown keys are reported with the file and line they came from.
+func NewMerge(paths *[]string, opts ...Opt) *Provider {
+	return newProvider(&Provider{paths: paths}, opts)
+}
+
+// NewDir creates a Provider reading all files of a directory matching WithPatterns (e.g. conf.d).
+//
+// Files are merged in lexical order as with NewMerge, so later files override earlier ones.
+// Each key is reported with the file that supplied it, e.g. "yaml[conf.d/10-db.yaml]".
+func NewDir(dir *string, opts ...Opt) *Provider {
+	return newProvider(&Provider{dir: dir, patterns: []string{"*.yaml", "*.yml"}}, opts)
+}
+
+func newProvider(p *Provider, opts []Opt) *Provider {
+	for _, opt := range opts {
+		opt(p)
+	}
+
+	return p
 }
 
 func (p *Provider) Type() string {
-	return fmt.Sprintf("yaml[%s]", util.ShortenPath(*p.path))
+	if p.dir != nil {
+		return fmt.Sprintf("yaml[%s]", util.ShortenPath(*p.dir))
+	}
+
+	files, _ := p.files()
+
+	short := make([]string, 0, len(files))
+	for _, f := range files {
+		short = append(short, util.ShortenPath(f))
+	}
+
+	return fmt.Sprintf("yaml[%s]", strings.Join(short, ","))
+}
+
+// Locate returns the position of a key as "file:line:column".
+// If several files define the key, the position in the last one is returned.
+func (p *Provider) Locate(key string) (string, bool) {
+	loc, ok := p.origin[key]
+	return loc, ok
+}
+
+// Source returns the name of the file a key came from, e.g. "yaml[conf.d/10-db.yaml]".
+func (p *Provider) Source(key string) (string, bool) {
+	file, ok := p.keyFile[key]
+	if !ok {
+		return "", false
+	}
+
+	return fmt.Sprintf("yaml[%s]", util.ShortenPath(file)), true
 }
 
 func (p *Provider) Provide(keys map[string]bool, conv func(any) string) (found, unknown map[string]string, err error) {
-	data, err := os.ReadFile(*p.path)
+	p.origin = make(map[string]string)
+	p.keyFile = make(map[string]string)
+	p.fileOf = make(map[*yaml.Node]string)
+
+	files, err := p.files()
 	if err != nil {
-		return nil, nil, fmt.Errorf("read yaml file: %w", err)
+		return nil, nil, err
 	}
 
-	p.conv = conv
-	p.awaited = keys
+	settings := make(map[string]any)
+	for _, path := range files {
+		if path == "" && p.optional {
+			continue
+		}
 
-	return p.parse(data)
-}
+		data, err := util.ReadFile(p.fsys, path)
+		if errors.Is(err, fs.ErrNotExist) && p.optional {
+			continue
+		}
+		if err != nil {
+			return nil, nil, fmt.Errorf("read yaml file: %w", err)
+		}
 
-func (p *Provider) parse(data []byte) (found, unknown map[string]string, err error) {
-	var settings map[string]any
-	if err = yaml.Unmarshal(data, &settings); err != nil {
-		return nil, nil, fmt.Errorf("unmarshal yaml: %w", err)
+		s, err := p.parse(data, path)
+		if err != nil {
+			return nil, nil, fmt.Errorf("%s: %w", path, err)
+		}
+
+		p.merge(settings, s)
 	}
 
-	found, unknown = p.flatten(settings)
+	found, unknown = util.Flatten(settings, keys, conv)
 
 	return found, unknown, nil
 }
 
-func (p *Provider) flatten(settings map[string]any) (found, unknown map[string]string) {
-	found, unknown = make(map[string]string), make(map[string]string)
+func (p *Provider) files() ([]string, error) {
+	switch {
+	case p.dir != nil:
+		return p.dirFiles()
+	case p.paths != nil:
+		return *p.paths, nil
+	default:
+		return []string{*p.path}, nil
+	}
+}
+
+func (p *Provider) dirFiles() ([]string, error) {
+	if *p.dir == "" && p.optional {
+		return nil, nil
+	}
 
-	p.flattenDFS(settings, "", found, unknown)
+	_, err := util.Stat(p.fsys, *p.dir)
+	if errors.Is(err, fs.ErrNotExist) && p.optional {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("read yaml dir: %w", err)
+	}
 
-	return found, unknown
+	var files []string
+	for _, pattern := range p.patterns {
+		matches, err := util.Glob(p.fsys, util.Join(p.fsys, *p.dir, pattern))
+		if err != nil {
+			return nil, fmt.Errorf("read yaml dir: %w", err)
+		}
+
+		files = append(files, matches...)
+	}
+	sort.Strings(files)
+
+	return files, nil
 }
 
-func (p *Provider) flattenDFS(m map[string]any, prefix string, found, unknown map[string]string) {
-	for k, v := range m {
-		newKey := k
-		if prefix != "" {
-			newKey = prefix + "." + k
+// Decode parses a yaml document into nested settings, includes are not supported.
+func Decode(data []byte) (map[string]any, error) {
+	var settings map[string]any
+	if err := yaml.Unmarshal(data, &settings); err != nil {
+		return nil, fmt.Errorf("unmarshal yaml: %w", err)
+	}
+
+	return settings, nil
+}
+
+func (p *Provider) parse(data []byte, path string) (map[string]any, error) {
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("unmarshal yaml: %w", err)
+	}
+
+	if len(doc.Content) == 0 {
+		return nil, nil
+	}
+
+	root, err := p.resolveIncludes(doc.Content[0], path, []string{util.Clean(p.fsys, path)})
+	if err != nil {
+		return nil, err
+	}
+	doc.Content[0] = root
+
+	if p.expandEnv {
+		if err := p.expandNode(root, path); err != nil {
+			return nil, err
 		}
+	}
 
-		if p.awaited[newKey] {
-			found[newKey] = p.conv(v)
+	var settings map[string]any
+	if err := doc.Decode(&settings); err != nil {
+		return nil, fmt.Errorf("unmarshal yaml: %w", err)
+	}
 
-			continue
+	p.track(root, "", path)
+
+	return settings, nil
+}
+
+// track records the position of every key path of a mapping node.
+// Scalars are located by their value, other nodes by their key.
+func (p *Provider) track(n *yaml.Node, prefix, path string) {
+	if n.Kind == yaml.AliasNode {
+		n = n.Alias
+	}
+
+	if n.Kind != yaml.MappingNode {
+		return
+	}
+
+	for i := 0; i+1 < len(n.Content); i += 2 {
+		k, v := n.Content[i], n.Content[i+1]
+
+		newKey := k.Value
+		if prefix != "" {
+			newKey = prefix + "." + k.Value
+		}
+
+		pos := k
+		if v.Kind == yaml.ScalarNode {
+			pos = v
 		}
+		p.origin[newKey] = p.position(pos, path)
+		p.keyFile[newKey] = p.file(pos, path)
+
+		p.track(v, newKey, path)
+	}
+}
 
-		if subMap, ok := v.(map[string]interface{}); ok {
-			p.flattenDFS(subMap, newKey, found, unknown)
+// position formats the position of a node as "file:line:column",
+// path is used unless the node comes from an included file.
+func (p *Provider) position(n *yaml.Node, path string) string {
+	return fmt.Sprintf("%s:%d:%d", p.file(n, path), n.Line, n.Column)
+}
 
-			continue
+func (p *Provider) file(n *yaml.Node, path string) string {
+	if f, ok := p.fileOf[n]; ok {
+		return f
+	}
+
+	return path
+}
+
+// merge deep-merges src into dst.
+func (p *Provider) merge(dst, src map[string]any) {
+	for k, v := range src {
+		switch sv := v.(type) {
+		case map[string]any:
+			if dv, ok := dst[k].(map[string]any); ok {
+				p.merge(dv, sv)
+
+				continue
+			}
+		case []any:
+			if dv, ok := dst[k].([]any); ok && p.lists == ListAppend {
+				dst[k] = append(dv, sv...)
+
+				continue
+			}
 		}
 
-		unknown[newKey] = p.conv(v)
+		dst[k] = v
 	}
 }
@@ -2,9 +2,12 @@ package yaml_test
 
 import (
 	"os"
+	"path/filepath"
 	"testing"
+	"testing/fstest"
 
 	zfg "github.com/chaindead/zerocfg"
+	"github.com/chaindead/zerocfg/util"
 	"github.com/chaindead/zerocfg/yaml"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -110,6 +113,356 @@ func TestParse_Error(t *testing.T) {
 	assert.Error(t, err)
 }
 
+func TestLocate(t *testing.T) {
+	name := tempFile(t, `
+db:
+  port: abc
+  hosts:
+    - a
+anchor: &a
+  k: v
+alias: *a`)
+	p := yaml.New(&name)
+
+	_, _, err := p.Provide(map[string]bool{"db.port": true}, zfg.ToString)
+	require.NoError(t, err)
+
+	tests := map[string]string{
+		"db":       ":2:1",
+		"db.port":  ":3:9",
+		"db.hosts": ":4:3",
+		"anchor.k": ":7:6",
+		"alias.k":  ":7:6",
+	}
+	for key, pos := range tests {
+		loc, ok := p.Locate(key)
+		assert.True(t, ok, key)
+		assert.Equal(t, name+pos, loc, key)
+	}
+
+	_, ok := p.Locate("missing")
+	assert.False(t, ok)
+}
+
+func TestExpandEnv(t *testing.T) {
+	t.Setenv("ZFG_TEST_HOST", "db.local")
+	t.Setenv("ZFG_TEST_PORT", "6543")
+	t.Setenv("ZFG_TEST_EMPTY", "")
+	t.Setenv("ZFG_TEST_NULL", "null")
+	t.Setenv("ZFG_TEST_OCTAL", "0123")
+	t.Setenv("ZFG_TEST_FLOAT", "1e3")
+
+	tests := []struct {
+		name  string
+		input string
+		found string
+		err   string
+	}{
+		{name: "defined", input: `v: ${ZFG_TEST_HOST}`, found: "db.local"},
+		{name: "inline", input: `v: "http://${ZFG_TEST_HOST}:${ZFG_TEST_PORT}/"`, found: "http://db.local:6543/"},
+		{name: "default unused", input: `v: ${ZFG_TEST_PORT:-5432}`, found: "6543"},
+		{name: "default unset", input: `v: ${ZFG_TEST_UNSET:-5432}`, found: "5432"},
+		{name: "default empty", input: `v: ${ZFG_TEST_EMPTY:-5432}`, found: "5432"},
+		{name: "escape", input: `v: pa$$word`, found: "pa$word"},
+		{name: "lone dollar", input: `v: $5`, found: "$5"},
+		{name: "list", input: "v:\n  - ${ZFG_TEST_PORT}\n  - 1", found: `["6543",1]`},
+		{name: "empty", input: `v: ${ZFG_TEST_EMPTY}`, found: ""},
+		{name: "null", input: `v: ${ZFG_TEST_NULL}`, found: "null"},
+		{name: "tilde", input: `v: ${ZFG_TEST_UNSET:-~}`, found: "~"},
+		{name: "leading zero", input: `v: ${ZFG_TEST_OCTAL}`, found: "0123"},
+		{name: "exponent", input: `v: ${ZFG_TEST_FLOAT}`, found: "1e3"},
+		{name: "undefined", input: `v: ${ZFG_TEST_UNSET}`, err: `:1:4: undefined variable "ZFG_TEST_UNSET"`},
+		{name: "unterminated", input: `v: ${ZFG_TEST_HOST`, err: "unterminated placeholder"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			name := tempFile(t, tt.input)
+
+			found, _, err := yaml.New(&name, yaml.ExpandEnv()).Provide(map[string]bool{"v": true}, zfg.ToString)
+			if tt.err != "" {
+				require.ErrorContains(t, err, tt.err)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, map[string]string{"v": tt.found}, found)
+		})
+	}
+
+	name := tempFile(t, `v: ${ZFG_TEST_UNSET}`)
+	found, _, err := yaml.New(&name).Provide(map[string]bool{"v": true}, zfg.ToString)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"v": "${ZFG_TEST_UNSET}"}, found)
+}
+
+func TestInclude(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, dir, "db.yaml", "host: localhost\nport: 5432")
+	writeFile(t, dir, "conf.d/10-a.yaml", "a: 1\nshared:\n  x: 1")
+	writeFile(t, dir, "conf.d/20-b.yaml", "b: 2\nshared:\n  x: 2\n  y: 2")
+	writeFile(t, dir, "nested/outer.yaml", "inner: !include inner/leaf.yaml")
+	writeFile(t, dir, "nested/inner/leaf.yaml", "leaf: true")
+	root := writeFile(t, dir, "config.yaml", `
+db: !include db.yaml
+extra: !include conf.d/*.yaml
+nested: !include nested/outer.yaml
+empty: !include conf.d/*.none`)
+
+	p := yaml.New(&root)
+	found, unknown, err := p.Provide(map[string]bool{
+		"db.host":      true,
+		"db.port":      true,
+		"extra.a":      true,
+		"extra.b":      true,
+		"extra.shared": true,
+	}, zfg.ToString)
+	require.NoError(t, err)
+
+	assert.Equal(t, map[string]string{
+		"db.host":      "localhost",
+		"db.port":      "5432",
+		"extra.a":      "1",
+		"extra.b":      "2",
+		"extra.shared": `{"x":2,"y":2}`,
+	}, found)
+	assert.Equal(t, map[string]string{"nested.inner.leaf": "true"}, unknown)
+
+	loc, ok := p.Locate("db.port")
+	assert.True(t, ok)
+	assert.Equal(t, filepath.Join(dir, "db.yaml")+":2:7", loc)
+
+	loc, ok = p.Locate("nested.inner.leaf")
+	assert.True(t, ok)
+	assert.Equal(t, filepath.Join(dir, "nested/inner/leaf.yaml")+":1:7", loc)
+}
+
+func TestInclude_Error(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, dir, "a.yaml", "b: !include b.yaml")
+	writeFile(t, dir, "b.yaml", "a: !include a.yaml")
+	writeFile(t, dir, "list.yaml", "- 1")
+	writeFile(t, dir, "glob/list.yaml", "- 1")
+
+	tests := []struct {
+		name  string
+		input string
+		err   []string
+	}{
+		{
+			name:  "cycle",
+			input: "a: !include a.yaml",
+			err:   []string{"include cycle", filepath.Join(dir, "a.yaml") + " -> " + filepath.Join(dir, "b.yaml") + " -> " + filepath.Join(dir, "a.yaml")},
+		},
+		{
+			name:  "missing",
+			input: "a: !include missing.yaml",
+			err:   []string{`:1:4: include "missing.yaml"`, "no such file"},
+		},
+		{
+			name:  "glob not mapping",
+			input: "a: !include glob/*.yaml",
+			err:   []string{"not a mapping"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			name := writeFile(t, dir, "root.yaml", tt.input)
+
+			_, _, err := yaml.New(&name).Provide(map[string]bool{}, zfg.ToString)
+			require.Error(t, err)
+			for _, e := range tt.err {
+				assert.Contains(t, err.Error(), e)
+			}
+		})
+	}
+}
+
+func TestDir(t *testing.T) {
+	dir := t.TempDir()
+	a := writeFile(t, dir, "10-a.yaml", "db:\n  host: a\n  port: 1\nstray: 1")
+	b := writeFile(t, dir, "20-b.yml", "db:\n  host: b")
+	writeFile(t, dir, "ignored.txt", "db:\n  host: c")
+
+	p := yaml.NewDir(&dir)
+	found, unknown, err := p.Provide(map[string]bool{"db.host": true, "db.port": true}, zfg.ToString)
+	require.NoError(t, err)
+
+	assert.Equal(t, map[string]string{"db.host": "b", "db.port": "1"}, found)
+	assert.Equal(t, map[string]string{"stray": "1"}, unknown)
+
+	tests := map[string]string{
+		"db.host": b,
+		"db.port": a,
+		"stray":   a,
+	}
+	for key, file := range tests {
+		source, ok := p.Source(key)
+		assert.True(t, ok)
+		assert.Equal(t, "yaml["+util.ShortenPath(file)+"]", source)
+	}
+
+	found, _, err = yaml.NewDir(&dir, yaml.WithPatterns("*.txt")).Provide(map[string]bool{"db.host": true}, zfg.ToString)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"db.host": "c"}, found)
+
+	missing := filepath.Join(dir, "missing")
+	_, _, err = yaml.NewDir(&missing).Provide(map[string]bool{}, zfg.ToString)
+	assert.Error(t, err)
+
+	_, _, err = yaml.NewDir(&missing, yaml.Optional()).Provide(map[string]bool{}, zfg.ToString)
+	assert.NoError(t, err)
+}
+
+func TestFS(t *testing.T) {
+	fsys := fstest.MapFS{
+		"defaults/config.yaml": {Data: []byte("db: !include db.yaml\nname: app")},
+		"defaults/db.yaml":     {Data: []byte("host: localhost")},
+		"conf.d/10-a.yaml":     {Data: []byte("a: 1")},
+		"conf.d/20-b.yaml":     {Data: []byte("a: 2")},
+	}
+
+	path := "defaults/config.yaml"
+	p := yaml.New(&path, yaml.WithFS(fsys))
+
+	found, _, err := p.Provide(map[string]bool{"db.host": true, "name": true}, zfg.ToString)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"db.host": "localhost", "name": "app"}, found)
+
+	loc, ok := p.Locate("db.host")
+	assert.True(t, ok)
+	assert.Equal(t, "defaults/db.yaml:1:7", loc)
+
+	dir := "conf.d"
+	found, _, err = yaml.NewDir(&dir, yaml.WithFS(fsys)).Provide(map[string]bool{"a": true}, zfg.ToString)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"a": "2"}, found)
+
+	missing := "missing.yaml"
+	_, _, err = yaml.New(&missing, yaml.WithFS(fsys)).Provide(map[string]bool{}, zfg.ToString)
+	assert.Error(t, err)
+
+	_, _, err = yaml.New(&missing, yaml.WithFS(fsys), yaml.Optional()).Provide(map[string]bool{}, zfg.ToString)
+	assert.NoError(t, err)
+}
+
+func TestOptional(t *testing.T) {
+	dir := t.TempDir()
+	missing := filepath.Join(dir, "missing.yaml")
+	empty := ""
+
+	for _, path := range []*string{&missing, &empty} {
+		_, _, err := yaml.New(path).Provide(map[string]bool{}, zfg.ToString)
+		require.Error(t, err)
+
+		found, unknown, err := yaml.New(path, yaml.Optional()).Provide(map[string]bool{}, zfg.ToString)
+		require.NoError(t, err)
+		assert.Empty(t, found)
+		assert.Empty(t, unknown)
+	}
+
+	existing := tempFile(t, `a: 1`)
+	files := []string{missing, existing}
+	found, _, err := yaml.NewMerge(&files, yaml.Optional()).Provide(map[string]bool{"a": true}, zfg.ToString)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"a": "1"}, found)
+
+	bad := tempFile(t, `invalid: [yaml: "missing closing quote`)
+	_, _, err = yaml.New(&bad, yaml.Optional()).Provide(map[string]bool{}, zfg.ToString)
+	assert.Error(t, err)
+
+	dirPath := dir
+	_, _, err = yaml.New(&dirPath, yaml.Optional()).Provide(map[string]bool{}, zfg.ToString)
+	assert.Error(t, err)
+}
+
+func TestMerge(t *testing.T) {
+	base := tempFile(t, `
+db:
+  host: localhost
+  port: 5432
+tags: [a, b]
+limits:
+  max: 10
+stray: 1`)
+	prod := tempFile(t, `
+db:
+  host: prod
+tags: [c]
+limits:
+  min: 1
+other: 2`)
+
+	awaited := map[string]bool{
+		"db.host": true,
+		"db.port": true,
+		"tags":    true,
+		"limits":  true,
+	}
+
+	tests := []struct {
+		name  string
+		opts  []yaml.Opt
+		found map[string]string
+	}{
+		{
+			name: "replace lists",
+			found: map[string]string{
+				"db.host": "prod",
+				"db.port": "5432",
+				"tags":    `["c"]`,
+				"limits":  `{"max":10,"min":1}`,
+			},
+		},
+		{
+			name: "append lists",
+			opts: []yaml.Opt{yaml.WithListMerge(yaml.ListAppend)},
+			found: map[string]string{
+				"db.host": "prod",
+				"db.port": "5432",
+				"tags":    `["a","b","c"]`,
+				"limits":  `{"max":10,"min":1}`,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			files := []string{base, prod}
+			p := yaml.NewMerge(&files, tt.opts...)
+
+			found, unknown, err := p.Provide(awaited, zfg.ToString)
+			require.NoError(t, err)
+
+			assert.Equal(t, tt.found, found)
+			assert.Equal(t, map[string]string{"stray": "1", "other": "2"}, unknown)
+
+			loc, ok := p.Locate("stray")
+			assert.True(t, ok)
+			assert.Equal(t, base+":8:8", loc)
+
+			loc, ok = p.Locate("other")
+			assert.True(t, ok)
+			assert.Equal(t, prod+":7:8", loc)
+
+			loc, ok = p.Locate("db.host")
+			assert.True(t, ok)
+			assert.Equal(t, prod+":3:9", loc)
+		})
+	}
+}
+
+func TestMerge_Error(t *testing.T) {
+	ok := tempFile(t, `a: 1`)
+	bad := tempFile(t, `invalid: [yaml: "missing closing quote`)
+	files := []string{ok, bad}
+
+	_, _, err := yaml.NewMerge(&files).Provide(map[string]bool{}, zfg.ToString)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), bad)
+}
+
 func tempFile(t *testing.T, data string) string {
 	f, err := os.CreateTemp("", "tmpfile-")
 	require.NoError(t, err)
@@ -122,3 +475,11 @@ func tempFile(t *testing.T, data string) string {
 
 	return f.Name()
 }
+
+func writeFile(t *testing.T, dir, name, data string) string {
+	path := filepath.Join(dir, name)
+	require.NoError(t, os.MkdirAll(filepath.Dir(path), 0o755))
+	require.NoError(t, os.WriteFile(path, []byte(data), 0o644))
+
+	return path
+}
@@ -138,6 +138,12 @@ func Test_ValueOk(t *testing.T) {
 				return regSource(Durs, []time.Duration{time.Second, 2 * time.Minute, 3 * time.Hour})
 			},
 		},
+		{
+			varType: "time",
+			init: func() (func() any, any, map[string]any) {
+				return regSource(Time, time.Date(2024, 5, 27, 7, 32, 0, 999, time.UTC))
+			},
+		},
 		{
 			varType: "ip",
 			init: func() (func() any, any, map[string]any) {
@@ -190,3 +196,22 @@ func Test_ValueOk(t *testing.T) {
 		})
 	}
 }
+
+func Test_TimeLayouts(t *testing.T) {
+	tests := map[string]time.Time{
+		"1979-05-27T07:32:00.5-07:00": time.Date(1979, 5, 27, 7, 32, 0, 5e8, time.FixedZone("", -7*3600)),
+		"1979-05-27T07:32:00":         time.Date(1979, 5, 27, 7, 32, 0, 0, time.UTC),
+		"1979-05-27 07:32:00":         time.Date(1979, 5, 27, 7, 32, 0, 0, time.UTC),
+		"1979-05-27":                  time.Date(1979, 5, 27, 0, 0, 0, 0, time.UTC),
+		"07:32:00":                    time.Date(0, 1, 1, 7, 32, 0, 0, time.UTC),
+	}
+
+	for s, expected := range tests {
+		var v time.Time
+		require.NoError(t, newTimeValue(time.Time{}, &v).Set(s), s)
+		require.True(t, expected.Equal(v), s)
+	}
+
+	var v time.Time
+	require.Error(t, newTimeValue(time.Time{}, &v).Set("yesterday"))
+}
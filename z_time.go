@@ -0,0 +1,57 @@
+package zerocfg
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// timeLayouts are accepted by Time options, local date and time values are parsed as UTC.
+var timeLayouts = []string{
+	time.RFC3339Nano,
+	"2006-01-02 15:04:05.999999999 -0700 MST", // time.Time.String
+	"2006-01-02T15:04:05.999999999",
+	"2006-01-02 15:04:05.999999999",
+	"2006-01-02",
+	"15:04:05.999999999",
+}
+
+type timeValue time.Time
+
+func newTimeValue(val time.Time, p *time.Time) Value {
+	*p = val
+	return (*timeValue)(p)
+}
+
+func (t *timeValue) Set(val string) error {
+	// drop monotonic clock reading of time.Time.String
+	val, _, _ = strings.Cut(val, " m=")
+
+	for _, layout := range timeLayouts {
+		parsed, err := time.Parse(layout, val)
+		if err == nil {
+			*t = timeValue(parsed)
+			return nil
+		}
+	}
+
+	return fmt.Errorf("time %q is not in RFC 3339 format", val)
+}
+
+func (t timeValue) String() string {
+	return time.Time(t).Format(time.RFC3339Nano)
+}
+
+func (t *timeValue) Type() string {
+	return "time"
+}
+
+// Time registers a time.Time configuration option and returns a pointer to its value.
+// Values are parsed as RFC 3339, local date-times, dates and times are accepted as well.
+//
+// Usage:
+//
+//	since := zerocfg.Time("since", time.Time{}, "start of the report")
+func Time(name string, defVal time.Time, desc string, opts ...OptNode) *time.Time {
+	return Any(name, defVal, desc, newTimeValue, opts...)
+}